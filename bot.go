@@ -27,6 +27,15 @@ type Bot struct {
 
 	l *slog.Logger
 
+	workers      int
+	queueSize    int
+	queueTimeout time.Duration
+	timeouts     map[plugin.Hook]time.Duration
+	calls        callTracker
+
+	middlewares   []Middleware
+	ephemeralHelp bool
+
 	helloOnce sync.Once
 }
 
@@ -77,6 +86,12 @@ func (b *Bot) init() error {
 		b.l = slog.New(slog.NewTextHandler(os.Stdout, nil))
 	}
 
+	// recover panics of plugins and middlewares
+	b.middlewares = append([]Middleware{Recoverer(b.l)}, b.middlewares...)
+	if h, ok := b.service.(service.EphemeralHelper); ok && b.ephemeralHelp {
+		h.SetEphemeralHelp(true)
+	}
+
 	return nil
 }
 
@@ -87,6 +102,13 @@ func (b *Bot) Close() error {
 		}
 	}
 
+	for _, call := range b.calls.leaked() {
+		b.l.Warn("plugin call is still running after timeout",
+			slog.String("plugin", call.plugin),
+			slog.String("hook", call.hook.String()),
+			slog.Duration("elapsed", time.Since(call.since)))
+	}
+
 	return b.db.Close()
 }
 
@@ -99,7 +121,7 @@ func (b *Bot) AddPlugin(p plugin.Plugin) {
 	b.plugins = append(b.plugins, p)
 }
 
-// Run runs the bot.
+// Run runs the bot until ctx is done, or the service closes the event channel.
 func (b *Bot) Run(ctx context.Context) error {
 	b.l.Info("Start to run bot service.")
 
@@ -110,102 +132,324 @@ func (b *Bot) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	defer b.service.Close()
 
-	b.loop(ctx, ch)
+	d := newDispatcher(b.workers, b.queueSize, b.queueTimeout, b.l)
+	d.start()
+	defer d.stop()
+
+	b.loop(ctx, ch, d)
 
 	return nil
 }
 
-func (b *Bot) loop(ctx context.Context, ch <-chan *service.Event) {
-	defer b.service.Close()
-
-loop:
+// loop handles the events until ctx is done or ch is closed.
+// The service is closed after the dispatcher is stopped,
+// so that the plugins can post messages until they finish the queued events.
+func (b *Bot) loop(ctx context.Context, ch <-chan *service.Event, d *dispatcher) {
 	for {
 		select {
 		case <-ctx.Done():
-			break loop
-		case event := <-ch:
-			switch event.Type {
-			case service.ConnectedEvent:
-				if hello := event.GetHello(); hello != nil {
-					b.hello(ctx, hello)
-				}
-			case service.MessageEvent:
-				if msg := event.GetMessage(); msg != nil {
-					b.doAction(ctx, msg)
-				}
+			return
+		case event, ok := <-ch:
+			if !ok {
+				b.l.Info("service has stopped")
+				return
 			}
+			b.handleEvent(ctx, event, d)
 		}
 	}
 }
 
+func (b *Bot) handleEvent(ctx context.Context, event *service.Event, d *dispatcher) {
+	switch event.Type {
+	case service.ConnectedEvent:
+		if hello := event.GetHello(); hello != nil {
+			b.hello(ctx, hello)
+			b.connected(ctx, hello)
+		}
+	case service.DisconnectedEvent:
+		b.disconnected(ctx)
+	case service.MessageEvent:
+		if msg := event.GetMessage(); msg != nil {
+			if d.dispatch(ctx, msg.ChannelID(), func(ctx context.Context) {
+				b.doAction(ctx, msg)
+			}, event.Finish) {
+				return
+			}
+		}
+	case service.MessageEditedEvent:
+		if msg := event.GetMessage(); msg != nil {
+			if d.dispatch(ctx, msg.ChannelID(), func(ctx context.Context) {
+				b.messageEdited(ctx, msg)
+			}, event.Finish) {
+				return
+			}
+		}
+	case service.MessageDeletedEvent:
+		if ref := event.GetMessageRef(); ref != nil {
+			if d.dispatch(ctx, ref.ChannelID, func(ctx context.Context) {
+				b.messageDeleted(ctx, *ref)
+			}, event.Finish) {
+				return
+			}
+		}
+	case service.DeliveryFailedEvent:
+		if f := event.GetDeliveryFailure(); f != nil {
+			if d.dispatch(ctx, f.ChannelID, func(ctx context.Context) {
+				b.deliveryFailed(ctx, f)
+			}, event.Finish) {
+				return
+			}
+		}
+	case service.ReactionEvent:
+		if r := event.GetReaction(); r != nil {
+			if d.dispatch(ctx, r.Target().ChannelID, func(ctx context.Context) {
+				b.handleReaction(ctx, r)
+			}, event.Finish) {
+				return
+			}
+		}
+	case service.InteractionEvent:
+		if i := event.GetInteraction(); i != nil {
+			if d.dispatch(ctx, i.Target().ChannelID, func(ctx context.Context) {
+				b.handleInteraction(ctx, i)
+			}, event.Finish) {
+				return
+			}
+		}
+	}
+
+	event.Finish()
+}
+
 func (b *Bot) hello(ctx context.Context, hello plugin.Hello) {
 	b.helloOnce.Do(func() {
 		for _, p := range b.plugins {
 			b.callPluginHello(ctx, p, hello)
 		}
+
+		b.registerCommands(ctx)
 	})
 }
 
-func (b *Bot) callPluginHello(ctx context.Context, plugin plugin.Plugin, hello plugin.Hello) {
-	defer func() {
-		if err := recover(); err != nil {
-			b.l.Error("recover plugin.Hello()", slog.Any("err", err))
+// registerCommands registers the commands of the plugins to the service,
+// if the service supports it.
+func (b *Bot) registerCommands(ctx context.Context) {
+	r, ok := b.service.(service.CommandRegistrar)
+	if !ok {
+		return
+	}
+
+	var helps []*plugin.Help
+	for _, p := range b.plugins {
+		if h := b.callPluginHelp(ctx, p); h != nil {
+			helps = append(helps, h)
 		}
-	}()
-
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	ch := make(chan struct{})
-	go func() {
-		plugin.Hello(ctx, hello)
-		close(ch)
-	}()
-
-	select {
-	case <-ch:
-	case <-ctx.Done():
-		if err := ctx.Err(); err != nil {
-			b.l.Error("abort plugin.Hello()", slog.Any("err", err))
+	}
+
+	if err := r.RegisterCommands(ctx, helps); err != nil {
+		b.l.Error("failed to register commands", slog.Any("err", err))
+	}
+}
+
+func (b *Bot) connected(ctx context.Context, hello plugin.Hello) {
+	b.l.Info("service has connected")
+
+	for _, p := range b.plugins {
+		if o, ok := p.(plugin.ConnectionObserver); ok {
+			req := &Request{
+				Hook:   plugin.ConnectedHook,
+				Plugin: p,
+				Hello:  hello,
+			}
+			b.callPlugin(ctx, req, func(ctx context.Context, req *Request) {
+				o.Connected(ctx, req.Hello)
+			})
 		}
 	}
 }
 
-func (b *Bot) doAction(ctx context.Context, msg plugin.Message) {
-	if msg.MentionTo(b.service.UserID()) && strings.Contains(msg.Text(), "help") {
-		b.postHelp(ctx, msg)
+func (b *Bot) disconnected(ctx context.Context) {
+	b.l.Warn("service has disconnected")
+
+	for _, p := range b.plugins {
+		if o, ok := p.(plugin.ConnectionObserver); ok {
+			req := &Request{
+				Hook:   plugin.DisconnectedHook,
+				Plugin: p,
+			}
+			b.callPlugin(ctx, req, func(ctx context.Context, req *Request) {
+				o.Disconnected(ctx)
+			})
+		}
+	}
+}
+
+func (b *Bot) deliveryFailed(ctx context.Context, f *plugin.DeliveryFailure) {
+	for _, p := range b.plugins {
+		if o, ok := p.(plugin.DeliveryObserver); ok {
+			req := &Request{
+				Hook:   plugin.DeliveryFailedHook,
+				Plugin: p,
+			}
+			b.callPlugin(ctx, req, func(ctx context.Context, req *Request) {
+				o.DeliveryFailed(ctx, f)
+			})
+		}
+	}
+}
+
+func (b *Bot) handleReaction(ctx context.Context, r plugin.Reaction) {
+	for _, p := range b.plugins {
+		if h, ok := p.(plugin.ReactionHandler); ok {
+			req := &Request{
+				Hook:     plugin.ReactionHook,
+				Plugin:   p,
+				Reaction: r,
+			}
+			b.callPlugin(ctx, req, func(ctx context.Context, req *Request) {
+				h.HandleReaction(ctx, req.Reaction)
+			})
+		}
+	}
+}
+
+func (b *Bot) handleInteraction(ctx context.Context, i plugin.Interaction) {
+	p, h := b.interactionHandler(i.ActionID())
+	if h == nil {
+		b.l.Warn("no plugin handles the interaction", slog.String("action_id", i.ActionID()))
 		return
 	}
 
+	req := &Request{
+		Hook:        plugin.InteractionHook,
+		Plugin:      p,
+		Interaction: i,
+	}
+	b.callPlugin(ctx, req, func(ctx context.Context, req *Request) {
+		h.HandleInteraction(ctx, req.Interaction)
+	})
+}
+
+// interactionHandler returns the plugin that has the longest prefix of the action ID.
+func (b *Bot) interactionHandler(actionID string) (plugin.Plugin, plugin.InteractionHandler) {
+	var (
+		found   plugin.Plugin
+		handler plugin.InteractionHandler
+		longest = -1
+	)
+	for _, p := range b.plugins {
+		h, ok := p.(plugin.InteractionHandler)
+		if !ok {
+			continue
+		}
+		for _, prefix := range h.ActionIDPrefixes() {
+			if strings.HasPrefix(actionID, prefix) && len(prefix) > longest {
+				found, handler, longest = p, h, len(prefix)
+			}
+		}
+	}
+
+	return found, handler
+}
+
+func (b *Bot) messageEdited(ctx context.Context, msg plugin.Message) {
 	for _, p := range b.plugins {
-		b.callPluginDoAction(ctx, p, msg)
+		if h, ok := p.(plugin.EditHandler); ok {
+			req := &Request{
+				Hook:    plugin.MessageEditedHook,
+				Plugin:  p,
+				Message: msg,
+			}
+			b.callPlugin(ctx, req, func(ctx context.Context, req *Request) {
+				h.MessageEdited(ctx, req.Message)
+			})
+		}
 	}
 }
 
-func (b *Bot) callPluginDoAction(ctx context.Context, plugin plugin.Plugin, msg plugin.Message) {
-	defer func() {
-		if err := recover(); err != nil {
-			b.l.Error("recover plugin.DoAction()", slog.Any("err", err))
+func (b *Bot) messageDeleted(ctx context.Context, ref plugin.MessageRef) {
+	for _, p := range b.plugins {
+		if h, ok := p.(plugin.DeleteHandler); ok {
+			req := &Request{
+				Hook:   plugin.MessageDeletedHook,
+				Plugin: p,
+			}
+			b.callPlugin(ctx, req, func(ctx context.Context, req *Request) {
+				h.MessageDeleted(ctx, ref)
+			})
 		}
-	}()
-
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	ch := make(chan struct{})
-	go func() {
-		plugin.DoAction(ctx, msg)
-		close(ch)
-	}()
-
-	select {
-	case <-ch:
-	case <-ctx.Done():
-		if err := ctx.Err(); err != nil {
-			b.l.Error("abort plugin.DoAction()", slog.Any("err", err))
+	}
+}
+
+func (b *Bot) callPluginHello(ctx context.Context, p plugin.Plugin, hello plugin.Hello) {
+	req := &Request{
+		Hook:   plugin.HelloHook,
+		Plugin: p,
+		Hello:  hello,
+	}
+	b.callPlugin(ctx, req, func(ctx context.Context, req *Request) {
+		req.Plugin.Hello(ctx, req.Hello)
+	})
+}
+
+func (b *Bot) doAction(ctx context.Context, msg plugin.Message) {
+	if msg.MentionTo(b.service.UserID()) && isHelpRequest(msg.Text()) {
+		b.callPluginDoAction(ctx, &helpPlugin{bot: b}, msg)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range b.plugins {
+		wg.Add(1)
+		go func(p plugin.Plugin) {
+			defer wg.Done()
+			b.callPluginDoAction(ctx, p, msg)
+		}(p)
+	}
+	wg.Wait()
+}
+
+func (b *Bot) callPluginDoAction(ctx context.Context, p plugin.Plugin, msg plugin.Message) {
+	req := &Request{
+		Hook:    plugin.DoActionHook,
+		Plugin:  p,
+		Message: msg,
+	}
+	b.callPlugin(ctx, req, func(ctx context.Context, req *Request) {
+		req.Plugin.DoAction(ctx, req.Message)
+	})
+}
+
+// isHelpRequest returns true if the text is "help" except the mentions.
+func isHelpRequest(text string) bool {
+	var words []string
+	for _, word := range strings.Fields(text) {
+		if strings.HasPrefix(word, "@") || (strings.HasPrefix(word, "<@") && strings.HasSuffix(word, ">")) {
+			continue
 		}
+		words = append(words, word)
 	}
+
+	return len(words) == 1 && strings.EqualFold(words[0], "help")
+}
+
+// helpPlugin is the built-in plugin that posts the help of the plugins.
+// It is called through the middlewares like the other plugins,
+// but only when the bot is asked for help.
+type helpPlugin struct {
+	bot *Bot
+}
+
+func (p *helpPlugin) Hello(ctx context.Context, hello plugin.Hello) {}
+
+func (p *helpPlugin) DoAction(ctx context.Context, msg plugin.Message) {
+	p.bot.postHelp(ctx, msg)
+}
+
+func (p *helpPlugin) Help(ctx context.Context) *plugin.Help {
+	return nil
 }
 
 func (b *Bot) postHelp(ctx context.Context, msg plugin.Message) {
@@ -224,33 +468,27 @@ func (b *Bot) postHelp(ctx context.Context, msg plugin.Message) {
 	}
 
 	escaped := b.service.EscapeHelp(doc.String())
-	msg.Post(escaped)
+	if r, ok := msg.(plugin.EphemeralReplier); ok && b.ephemeralHelp {
+		r.PostEphemeral(escaped)
+	} else {
+		msg.Post(escaped)
+	}
 }
 
 func (b *Bot) callPluginHelp(ctx context.Context, p plugin.Plugin) *plugin.Help {
-	defer func() {
-		if err := recover(); err != nil {
-			b.l.Error("recover plugin.Help()", slog.Any("err", err))
-		}
-	}()
-
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	ch := make(chan *plugin.Help, 1)
-	go func() {
-		ch <- p.Help(ctx)
-	}()
-
-	select {
-	case help := <-ch:
-		return help
-	case <-ctx.Done():
-		if err := ctx.Err(); err != nil {
-			b.l.Error("abort plugin.Help()", slog.Any("err", err))
-		}
+	req := &Request{
+		Hook:   plugin.HelpHook,
+		Plugin: p,
+	}
+
+	var help *plugin.Help
+	if !b.callPlugin(ctx, req, func(ctx context.Context, req *Request) {
+		help = req.Plugin.Help(ctx)
+	}) {
 		return nil
 	}
+
+	return help
 }
 
 type Option func(bot *Bot)
@@ -266,3 +504,57 @@ func WithLogger(l *slog.Logger) Option {
 		bot.l = l
 	}
 }
+
+// WithMiddleware adds middlewares that intercept plugin hook calls.
+// Middlewares are called in the order they are added.
+func WithMiddleware(mws ...Middleware) Option {
+	return func(bot *Bot) {
+		bot.middlewares = append(bot.middlewares, mws...)
+	}
+}
+
+// WithTimeout sets the default timeout for the hook.
+// Plugins can override the timeout by implementing plugin.TimeoutProvider.
+func WithTimeout(hook plugin.Hook, d time.Duration) Option {
+	return func(bot *Bot) {
+		if bot.timeouts == nil {
+			bot.timeouts = make(map[plugin.Hook]time.Duration)
+		}
+		bot.timeouts[hook] = d
+	}
+}
+
+// WithEphemeralHelp makes help messages visible only to the user that requested them,
+// including usage messages posted by plugins with plugin.Message.PostHelp.
+// Help messages are posted to everyone on the services that do not implement
+// service.EphemeralHelper.
+func WithEphemeralHelp() Option {
+	return func(bot *Bot) {
+		bot.ephemeralHelp = true
+	}
+}
+
+// WithWorkers sets the number of workers that process messages.
+// Messages posted to the same channel are always processed in order by the same worker.
+func WithWorkers(n int) Option {
+	return func(bot *Bot) {
+		bot.workers = n
+	}
+}
+
+// WithQueueSize sets the number of messages that can be queued for each worker.
+// When the queue is full, the bot waits for the worker before reading the next event.
+func WithQueueSize(n int) Option {
+	return func(bot *Bot) {
+		bot.queueSize = n
+	}
+}
+
+// WithQueueTimeout sets how long the bot waits for a full queue of a worker.
+// Messages are dropped when the queue is still full after the timeout.
+// The default is 3 seconds.
+func WithQueueTimeout(d time.Duration) Option {
+	return func(bot *Bot) {
+		bot.queueTimeout = d
+	}
+}
@@ -0,0 +1,516 @@
+package bot_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kechako/gopher-bot/v2/bottest"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+)
+
+// funcPlugin is a plugin that calls the functions of its fields from the hooks.
+// Hooks whose functions are nil do nothing.
+type funcPlugin struct {
+	hello             func(ctx context.Context, hello plugin.Hello)
+	doAction          func(ctx context.Context, msg plugin.Message)
+	connected         func(ctx context.Context, hello plugin.Hello)
+	disconnected      func(ctx context.Context)
+	deliveryFailed    func(ctx context.Context, f *plugin.DeliveryFailure)
+	messageEdited     func(ctx context.Context, msg plugin.Message)
+	messageDeleted    func(ctx context.Context, ref plugin.MessageRef)
+	handleReaction    func(ctx context.Context, r plugin.Reaction)
+	actionIDPrefixes  []string
+	handleInteraction func(ctx context.Context, i plugin.Interaction)
+}
+
+var (
+	_ plugin.Plugin             = (*funcPlugin)(nil)
+	_ plugin.ConnectionObserver = (*funcPlugin)(nil)
+	_ plugin.DeliveryObserver   = (*funcPlugin)(nil)
+	_ plugin.EditHandler        = (*funcPlugin)(nil)
+	_ plugin.ReactionHandler    = (*funcPlugin)(nil)
+	_ plugin.InteractionHandler = (*funcPlugin)(nil)
+)
+
+func (p *funcPlugin) Hello(ctx context.Context, hello plugin.Hello) {
+	if p.hello != nil {
+		p.hello(ctx, hello)
+	}
+}
+
+func (p *funcPlugin) DoAction(ctx context.Context, msg plugin.Message) {
+	if p.doAction != nil {
+		p.doAction(ctx, msg)
+	}
+}
+
+func (p *funcPlugin) Help(ctx context.Context) *plugin.Help {
+	return nil
+}
+
+func (p *funcPlugin) Connected(ctx context.Context, hello plugin.Hello) {
+	if p.connected != nil {
+		p.connected(ctx, hello)
+	}
+}
+
+func (p *funcPlugin) Disconnected(ctx context.Context) {
+	if p.disconnected != nil {
+		p.disconnected(ctx)
+	}
+}
+
+func (p *funcPlugin) DeliveryFailed(ctx context.Context, f *plugin.DeliveryFailure) {
+	if p.deliveryFailed != nil {
+		p.deliveryFailed(ctx, f)
+	}
+}
+
+func (p *funcPlugin) MessageEdited(ctx context.Context, msg plugin.Message) {
+	if p.messageEdited != nil {
+		p.messageEdited(ctx, msg)
+	}
+}
+
+func (p *funcPlugin) MessageDeleted(ctx context.Context, ref plugin.MessageRef) {
+	if p.messageDeleted != nil {
+		p.messageDeleted(ctx, ref)
+	}
+}
+
+func (p *funcPlugin) HandleReaction(ctx context.Context, r plugin.Reaction) {
+	if p.handleReaction != nil {
+		p.handleReaction(ctx, r)
+	}
+}
+
+func (p *funcPlugin) ActionIDPrefixes() []string {
+	return p.actionIDPrefixes
+}
+
+func (p *funcPlugin) HandleInteraction(ctx context.Context, i plugin.Interaction) {
+	if p.handleInteraction != nil {
+		p.handleInteraction(ctx, i)
+	}
+}
+
+// recorder records calls of the plugin hooks.
+type recorder struct {
+	calls []string
+	mux   sync.Mutex
+}
+
+func (r *recorder) record(call string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.calls = append(r.calls, call)
+}
+
+func (r *recorder) Calls() []string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return append([]string(nil), r.calls...)
+}
+
+// observerPlugin returns a plugin that records calls of the observer hooks to r.
+func observerPlugin(r *recorder) *funcPlugin {
+	return &funcPlugin{
+		hello: func(ctx context.Context, hello plugin.Hello) {
+			r.record("Hello")
+		},
+		connected: func(ctx context.Context, hello plugin.Hello) {
+			r.record("Connected")
+		},
+		disconnected: func(ctx context.Context) {
+			r.record("Disconnected")
+		},
+		deliveryFailed: func(ctx context.Context, f *plugin.DeliveryFailure) {
+			r.record("DeliveryFailed " + f.ChannelID + " " + f.Text + ": " + f.Err.Error())
+		},
+	}
+}
+
+// refPlugin returns a plugin that posts the reference to its own message on "ref".
+func refPlugin() *funcPlugin {
+	return &funcPlugin{
+		doAction: func(ctx context.Context, msg plugin.Message) {
+			if msg.Text() != "ref" {
+				return
+			}
+
+			ref, err := msg.(plugin.ContextReplier).PostContext(ctx, "hello")
+			if err != nil {
+				msg.Post(err.Error())
+				return
+			}
+			msg.Post(ref.ChannelID + "/" + ref.MessageID)
+		},
+	}
+}
+
+func Test_Bot_ConnectionObserver(t *testing.T) {
+	r := &recorder{}
+
+	h := bottest.New(t)
+	h.AddPlugin(observerPlugin(r))
+	h.Start()
+
+	h.Disconnect()
+	h.Reconnect()
+
+	want := []string{"Hello", "Connected", "Disconnected", "Connected"}
+	if diff := cmp.Diff(r.Calls(), want); diff != "" {
+		t.Errorf("plugin calls differ: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_Bot_DeliveryObserver(t *testing.T) {
+	r := &recorder{}
+
+	h := bottest.New(t)
+	h.AddPlugin(observerPlugin(r))
+	h.Start()
+
+	h.FailDelivery("general", "hello", errors.New("channel_not_found"))
+
+	want := []string{"Hello", "Connected", "DeliveryFailed general hello: channel_not_found"}
+	if diff := cmp.Diff(r.Calls(), want); diff != "" {
+		t.Errorf("plugin calls differ: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_Message_PostContext(t *testing.T) {
+	h := bottest.New(t)
+	h.AddPlugin(refPlugin())
+	h.Start()
+
+	h.Send("general", "alice", "ref")
+	posts := h.Send("random", "alice", "ref")
+
+	want := []string{"hello", "random/3"}
+	if diff := cmp.Diff(bottest.Texts(posts), want); diff != "" {
+		t.Errorf("posts differ: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_Bot_Edit(t *testing.T) {
+	var bot plugin.Bot
+
+	// edits and deletes its own messages
+	p := &funcPlugin{
+		hello: func(ctx context.Context, hello plugin.Hello) {
+			bot = hello.Bot()
+		},
+		doAction: func(ctx context.Context, msg plugin.Message) {
+			ref, err := msg.(plugin.ContextReplier).PostContext(ctx, "working...")
+			if err != nil {
+				msg.Post(err.Error())
+				return
+			}
+
+			switch msg.Text() {
+			case "done":
+				err = bot.(plugin.Editor).Edit(ref, "done")
+			case "cancel":
+				err = bot.(plugin.Editor).Delete(ref)
+			}
+			if err != nil {
+				msg.Post(err.Error())
+			}
+		},
+	}
+
+	h := bottest.New(t)
+	h.AddPlugin(p)
+	h.Start()
+
+	h.Send("general", "alice", "done")
+	h.Send("general", "alice", "cancel")
+
+	want := []*bottest.Post{
+		{ID: "1", ChannelID: "general", Text: "done", Edited: true},
+		{ID: "2", ChannelID: "general", Text: "working...", Deleted: true},
+	}
+	if diff := cmp.Diff(h.Posts(), want); diff != "" {
+		t.Errorf("posts differ: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_Bot_ReactionHandler(t *testing.T) {
+	var (
+		bot   plugin.Bot
+		votes int
+	)
+
+	// posts a vote, and counts reactions to it
+	p := &funcPlugin{
+		hello: func(ctx context.Context, hello plugin.Hello) {
+			bot = hello.Bot()
+		},
+		doAction: func(ctx context.Context, msg plugin.Message) {
+			ref, err := msg.(plugin.ContextReplier).PostContext(ctx, "vote")
+			if err != nil {
+				msg.Post(err.Error())
+				return
+			}
+
+			if err := msg.(plugin.Reactor).React(ref, "+1"); err != nil {
+				msg.Post(err.Error())
+			}
+		},
+		handleReaction: func(ctx context.Context, r plugin.Reaction) {
+			if r.Added() {
+				votes++
+			} else {
+				votes--
+			}
+
+			bot.Post(r.Target().ChannelID, fmt.Sprintf("%s %s: %d", r.UserID(), r.Emoji(), votes))
+		},
+	}
+
+	h := bottest.New(t)
+	h.AddPlugin(p)
+	h.Start()
+
+	h.Send("general", "alice", "vote")
+
+	ref := plugin.MessageRef{ChannelID: "general", MessageID: "1"}
+	wantReactions := []*bottest.Reaction{
+		{Target: ref, Emoji: "+1"},
+	}
+	if diff := cmp.Diff(h.Reactions(), wantReactions); diff != "" {
+		t.Errorf("reactions differ: (-got +want)\n%s", diff)
+	}
+
+	var got []string
+	got = append(got, bottest.Texts(h.AddReaction(ref, "alice", "+1"))...)
+	got = append(got, bottest.Texts(h.AddReaction(ref, "bob", "+1"))...)
+	got = append(got, bottest.Texts(h.RemoveReaction(ref, "alice", "+1"))...)
+
+	want := []string{"alice +1: 1", "bob +1: 2", "alice +1: 1"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("posts differ: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_Bot_EditHandler(t *testing.T) {
+	var bot plugin.Bot
+
+	// runs commands again on edit, and reports deleted messages
+	echo := func(ctx context.Context, msg plugin.Message) {
+		msg.Post("echo " + msg.Text())
+	}
+	p := &funcPlugin{
+		hello: func(ctx context.Context, hello plugin.Hello) {
+			bot = hello.Bot()
+		},
+		doAction:      echo,
+		messageEdited: echo,
+		messageDeleted: func(ctx context.Context, ref plugin.MessageRef) {
+			bot.Post(ref.ChannelID, "deleted "+ref.MessageID)
+		},
+	}
+
+	h := bottest.New(t)
+	h.AddPlugin(p, refPlugin())
+	h.Start()
+
+	var got []string
+	got = append(got, bottest.Texts(h.Send("general", "alice", "hello"))...)
+	// refPlugin does not handle edited messages
+	got = append(got, bottest.Texts(h.EditMessage("general", "alice", "ref"))...)
+	got = append(got, bottest.Texts(h.DeleteMessage(plugin.MessageRef{ChannelID: "general", MessageID: "100"}))...)
+
+	want := []string{"echo hello", "echo ref", "deleted 100"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("posts differ: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_Message_thread(t *testing.T) {
+	// posts to the thread and the channel
+	p := &funcPlugin{
+		doAction: func(ctx context.Context, msg plugin.Message) {
+			r := msg.(plugin.ThreadReplier)
+			msg.Post("post")
+			r.ReplyInThread("reply")
+			r.PostToChannel("channel")
+		},
+	}
+
+	h := bottest.New(t)
+	h.AddPlugin(p)
+	h.Start()
+
+	posts := h.SendToThread("general", "T1", "alice", "hello")
+
+	want := []*bottest.Post{
+		{ID: "1", ChannelID: "general", ThreadID: "T1", Text: "post"},
+		{ID: "2", ChannelID: "general", ThreadID: "T1", Text: "reply"},
+		{ID: "3", ChannelID: "general", Text: "channel"},
+	}
+	if diff := cmp.Diff(posts, want); diff != "" {
+		t.Errorf("posts differ: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_Bot_DirectMessage(t *testing.T) {
+	var bot plugin.Bot
+
+	// replies to messages in direct messages
+	p := &funcPlugin{
+		hello: func(ctx context.Context, hello plugin.Hello) {
+			bot = hello.Bot()
+		},
+		doAction: func(ctx context.Context, msg plugin.Message) {
+			if msg.(plugin.DirectChecker).IsDirect() {
+				msg.Post("direct")
+				return
+			}
+
+			if err := bot.(plugin.DirectMessenger).DirectMessage(msg.UserID(), "hello"); err != nil {
+				msg.Post(err.Error())
+			}
+		},
+	}
+
+	h := bottest.New(t)
+	h.AddPlugin(p)
+	h.Start()
+
+	h.Send("general", "alice", "hi")
+	h.Send(bottest.DirectChannel("alice"), "alice", "hi")
+
+	want := []*bottest.Post{
+		{ID: "1", ChannelID: "@alice", Text: "hello"},
+		{ID: "2", ChannelID: "@alice", Text: "direct"},
+	}
+	if diff := cmp.Diff(h.Posts(), want); diff != "" {
+		t.Errorf("posts differ: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_Bot_InteractionHandler(t *testing.T) {
+	var bot plugin.Bot
+
+	// posts a poll with buttons, and updates it on votes
+	poll := &funcPlugin{
+		doAction: func(ctx context.Context, msg plugin.Message) {
+			if msg.Text() != "poll" {
+				return
+			}
+
+			msg.(plugin.RichReplier).PostRich(rich.New("poll").Buttons(
+				&rich.Button{ActionID: "poll:vote", Label: "Yes", Value: "yes"},
+				&rich.Button{ActionID: "poll:vote", Label: "No", Value: "no"},
+				&rich.Button{ActionID: "poll:close", Label: "Close"},
+			))
+		},
+		actionIDPrefixes: []string{"poll:"},
+		handleInteraction: func(ctx context.Context, i plugin.Interaction) {
+			if err := i.Update(rich.New("poll").Section("", i.Value()+": "+i.UserID())); err != nil {
+				i.PostEphemeral(err.Error())
+				return
+			}
+			i.PostEphemeral("voted " + i.Value())
+		},
+	}
+	// handles the close button of the poll
+	closePoll := &funcPlugin{
+		hello: func(ctx context.Context, hello plugin.Hello) {
+			bot = hello.Bot()
+		},
+		actionIDPrefixes: []string{"poll:close"},
+		handleInteraction: func(ctx context.Context, i plugin.Interaction) {
+			bot.Post(i.Target().ChannelID, "closed by "+i.UserID())
+		},
+	}
+
+	h := bottest.New(t)
+	h.AddPlugin(poll, closePoll)
+	h.Start()
+
+	posts := h.Send("general", "alice", "poll")
+	if len(posts) != 1 || posts[0].Rich == nil {
+		t.Fatalf("rich message is not posted: %v", bottest.Texts(posts))
+	}
+	if want := "[Yes] [No] [Close]"; posts[0].Text != want {
+		t.Errorf("text of the rich message => %q, want %q", posts[0].Text, want)
+	}
+
+	ref := plugin.MessageRef{ChannelID: "general", MessageID: "1"}
+
+	var got []string
+	got = append(got, bottest.Texts(h.Interact(ref, "alice", "poll:vote", "yes"))...)
+	// the longest prefix wins
+	got = append(got, bottest.Texts(h.Interact(ref, "bob", "poll:close", ""))...)
+	// no plugin handles the action
+	got = append(got, bottest.Texts(h.Interact(ref, "bob", "unknown", ""))...)
+
+	want := []string{"voted yes", "closed by bob"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("posts differ: (-got +want)\n%s", diff)
+	}
+
+	updated := h.Posts()[0]
+	if !updated.Edited || updated.Text != "yes: alice" {
+		t.Errorf("updated message => %q (edited: %v), want %q", updated.Text, updated.Edited, "yes: alice")
+	}
+}
+
+func Test_Bot_Upload(t *testing.T) {
+	var bot plugin.Bot
+
+	// uploads the files attached to messages with upper-cased names
+	p := &funcPlugin{
+		hello: func(ctx context.Context, hello plugin.Hello) {
+			bot = hello.Bot()
+		},
+		doAction: func(ctx context.Context, msg plugin.Message) {
+			for _, a := range msg.(plugin.AttachmentProvider).Attachments() {
+				r, err := a.Open(ctx)
+				if err != nil {
+					msg.Post(err.Error())
+					continue
+				}
+
+				comment := fmt.Sprintf("%s, %d bytes", a.MIMEType(), a.Size())
+				if err := bot.(plugin.Uploader).Upload(msg.ChannelID(), strings.ToUpper(a.Name()), r, comment); err != nil {
+					msg.Post(err.Error())
+				}
+				r.Close()
+			}
+		},
+	}
+
+	h := bottest.New(t)
+	h.AddPlugin(p)
+	h.Start()
+
+	posts := h.SendWithFiles("general", "alice", "upload",
+		&bottest.File{Name: "cron.json", Data: []byte(`{"name":"job"}`)},
+		&bottest.File{Name: "log.txt", MIMEType: "text/plain", Data: []byte("error\n")},
+	)
+
+	want := []*bottest.Post{
+		{ID: "1", ChannelID: "general", Text: "application/json, 14 bytes", File: &bottest.File{Name: "CRON.JSON", Data: []byte(`{"name":"job"}`)}},
+		{ID: "2", ChannelID: "general", Text: "text/plain, 6 bytes", File: &bottest.File{Name: "LOG.TXT", Data: []byte("error\n")}},
+	}
+	if diff := cmp.Diff(posts, want); diff != "" {
+		t.Errorf("posts differ: (-got +want)\n%s", diff)
+	}
+
+	// messages without attachments
+	if posts := h.Send("general", "alice", "upload"); len(posts) != 0 {
+		t.Errorf("%d messages are posted, want 0", len(posts))
+	}
+}
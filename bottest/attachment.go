@@ -0,0 +1,78 @@
+package bottest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"path"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+// File represents a file uploaded by the bot, or attached to a message injected by SendWithFiles.
+type File struct {
+	// Name is the file name.
+	Name string
+	// MIMEType is the MIME type of the file.
+	// If it is empty, it is detected from the extension of Name.
+	MIMEType string
+	// Data is the content of the file.
+	Data []byte
+}
+
+// mimeType returns the MIME type of the file.
+func (f *File) mimeType() string {
+	if f.MIMEType != "" {
+		return f.MIMEType
+	}
+	if t := mime.TypeByExtension(path.Ext(f.Name)); t != "" {
+		return t
+	}
+
+	return "application/octet-stream"
+}
+
+type attachment struct {
+	file *File
+}
+
+var _ plugin.Attachment = (*attachment)(nil)
+
+// attachments returns the files as attachments of a message.
+func attachments(files []*File) []plugin.Attachment {
+	if len(files) == 0 {
+		return nil
+	}
+
+	attachments := make([]plugin.Attachment, len(files))
+	for i, f := range files {
+		attachments[i] = &attachment{file: f}
+	}
+
+	return attachments
+}
+
+// Name implements the plugin.Attachment interface.
+func (a *attachment) Name() string {
+	return a.file.Name
+}
+
+// MIMEType implements the plugin.Attachment interface.
+func (a *attachment) MIMEType() string {
+	return a.file.mimeType()
+}
+
+// Size implements the plugin.Attachment interface.
+func (a *attachment) Size() int64 {
+	return int64(len(a.file.Data))
+}
+
+// Open implements the plugin.Attachment interface.
+func (a *attachment) Open(ctx context.Context) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(a.file.Data)), nil
+}
@@ -0,0 +1,152 @@
+package bottest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kechako/gopher-bot/v2/cron"
+	robfig "github.com/robfig/cron/v3"
+)
+
+// Clock is a fake clock that only advances by Advance.
+// It implements cron.Clock.
+type Clock struct {
+	now     time.Time
+	entries []*entry
+	nextID  robfig.EntryID
+	running bool
+	mux     sync.Mutex
+}
+
+var _ cron.Clock = (*Clock)(nil)
+
+type entry struct {
+	id       robfig.EntryID
+	schedule robfig.Schedule
+	job      robfig.Job
+	next     time.Time
+}
+
+// NewClock returns a new *Clock of the time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{
+		now: now,
+	}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.now
+}
+
+// Schedule implements the cron.Clock interface.
+func (c *Clock) Schedule(schedule robfig.Schedule, job robfig.Job) robfig.EntryID {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.nextID++
+	e := &entry{
+		id:       c.nextID,
+		schedule: schedule,
+		job:      job,
+	}
+	if c.running {
+		e.next = schedule.Next(c.now)
+	}
+	c.entries = append(c.entries, e)
+
+	return e.id
+}
+
+// Remove implements the cron.Clock interface.
+func (c *Clock) Remove(id robfig.EntryID) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for i, e := range c.entries {
+		if e.id == id {
+			c.entries = append(c.entries[:i], c.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Start implements the cron.Clock interface.
+func (c *Clock) Start() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.running {
+		return
+	}
+	c.running = true
+	for _, e := range c.entries {
+		e.next = e.schedule.Next(c.now)
+	}
+}
+
+// Stop implements the cron.Clock interface.
+// The returned context is already done, since jobs are run synchronously by Advance.
+func (c *Clock) Stop() context.Context {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.running = false
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+// Advance advances the clock by the duration,
+// and runs the jobs that are due in order of their activation times.
+// Unlike *cron.Cron, jobs are run synchronously.
+func (c *Clock) Advance(d time.Duration) {
+	c.mux.Lock()
+	end := c.now.Add(d)
+	c.mux.Unlock()
+
+	for {
+		c.mux.Lock()
+		e := c.due(end)
+		if e == nil {
+			c.now = end
+			c.mux.Unlock()
+			return
+		}
+
+		c.now = e.next
+		e.next = e.schedule.Next(c.now)
+		c.mux.Unlock()
+
+		e.job.Run()
+	}
+}
+
+// due returns the entry that is activated first until the time, or nil if there is no entry.
+// c.mux must be held.
+func (c *Clock) due(end time.Time) *entry {
+	if !c.running {
+		return nil
+	}
+
+	sort.SliceStable(c.entries, func(i, j int) bool {
+		return c.entries[i].next.Before(c.entries[j].next)
+	})
+	for _, e := range c.entries {
+		if e.next.IsZero() {
+			continue
+		}
+		if e.next.After(end) {
+			return nil
+		}
+		return e
+	}
+
+	return nil
+}
@@ -0,0 +1,277 @@
+// Package bottest provides utilities to test plugins with a bot.
+//
+// A Harness runs a bot.Bot on an in-memory Service, injects messages
+// from arbitrary users and channels, and records messages posted by the bot:
+//
+//	h := bottest.New(t)
+//	h.AddPlugin(location.NewPlugin())
+//	h.Start()
+//
+//	posts := h.Send("general", "alice", "loc list")
+//
+// Conversations can also be written in a golden transcript file, see Harness.RunTranscript.
+package bottest
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	gopherbot "github.com/kechako/gopher-bot/v2"
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+// Timeout is the timeout to wait for the bot to handle events.
+var Timeout = 10 * time.Second
+
+// Harness runs a bot for tests.
+type Harness struct {
+	t       testing.TB
+	bot     *gopherbot.Bot
+	service *Service
+	clock   *Clock
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New returns a new *Harness. opts are passed to bot.New after the default options
+// that use a temporary database directory and discard logs.
+func New(t testing.TB, opts ...gopherbot.Option) *Harness {
+	t.Helper()
+
+	s := NewService()
+
+	opts = append([]gopherbot.Option{
+		gopherbot.WithDatabaseDir(t.TempDir()),
+		gopherbot.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	}, opts...)
+
+	b, err := gopherbot.New(s, opts...)
+	if err != nil {
+		t.Fatal("failed to create a bot: ", err)
+	}
+
+	return &Harness{
+		t:       t,
+		bot:     b,
+		service: s,
+		clock:   NewClock(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)),
+	}
+}
+
+// Bot returns the bot.
+func (h *Harness) Bot() *gopherbot.Bot {
+	return h.bot
+}
+
+// Service returns the in-memory service.
+func (h *Harness) Service() *Service {
+	return h.service
+}
+
+// Clock returns the fake clock.
+// It starts at 2023-01-01 00:00:00 UTC.
+func (h *Harness) Clock() *Clock {
+	return h.clock
+}
+
+// AddPlugin adds plugins to the bot. It must be called before Start.
+func (h *Harness) AddPlugin(plugins ...plugin.Plugin) {
+	for _, p := range plugins {
+		h.bot.AddPlugin(p)
+	}
+}
+
+// Start runs the bot, and waits until plugins are ready.
+// The bot is stopped when the test finishes.
+func (h *Harness) Start() {
+	h.t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	h.done = make(chan struct{})
+
+	go func() {
+		defer close(h.done)
+		if err := h.bot.Run(ctx); err != nil {
+			h.t.Error("failed to run the bot: ", err)
+		}
+	}()
+
+	h.t.Cleanup(h.stop)
+
+	h.wait()
+}
+
+func (h *Harness) stop() {
+	h.cancel()
+	<-h.done
+	h.bot.Close()
+}
+
+func (h *Harness) wait() {
+	h.t.Helper()
+
+	if err := h.service.Wait(Timeout); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+// Send sends a message posted by the user to the channel, and waits until the bot handles it.
+// Returns messages posted by the bot while handling the message.
+func (h *Harness) Send(channelID, userID, text string) []*Post {
+	h.t.Helper()
+
+	n := len(h.service.Posts())
+
+	h.service.Send(channelID, userID, text)
+	h.wait()
+
+	return h.service.Posts()[n:]
+}
+
+// SendWithFiles sends a message with the files attached, posted by the user to the channel,
+// and waits until the bot handles it.
+// Returns messages posted by the bot while handling the message.
+func (h *Harness) SendWithFiles(channelID, userID, text string, files ...*File) []*Post {
+	h.t.Helper()
+
+	n := len(h.service.Posts())
+
+	h.service.SendWithFiles(channelID, userID, text, files...)
+	h.wait()
+
+	return h.service.Posts()[n:]
+}
+
+// SendToThread sends a message posted by the user to the thread of the channel,
+// and waits until the bot handles it.
+// Returns messages posted by the bot while handling the message.
+func (h *Harness) SendToThread(channelID, threadID, userID, text string) []*Post {
+	h.t.Helper()
+
+	n := len(h.service.Posts())
+
+	h.service.SendToThread(channelID, threadID, userID, text)
+	h.wait()
+
+	return h.service.Posts()[n:]
+}
+
+// EditMessage edits a message of the user in the channel, and waits until the bot handles it.
+// Returns messages posted by the bot while handling the edited message.
+func (h *Harness) EditMessage(channelID, userID, text string) []*Post {
+	h.t.Helper()
+
+	n := len(h.service.Posts())
+
+	h.service.EditMessage(channelID, userID, text)
+	h.wait()
+
+	return h.service.Posts()[n:]
+}
+
+// DeleteMessage deletes a message of a user, and waits until the bot handles it.
+// Returns messages posted by the bot while handling the deleted message.
+func (h *Harness) DeleteMessage(ref plugin.MessageRef) []*Post {
+	h.t.Helper()
+
+	n := len(h.service.Posts())
+
+	h.service.DeleteMessage(ref)
+	h.wait()
+
+	return h.service.Posts()[n:]
+}
+
+// Advance advances the clock by the duration, and waits until the bot handles
+// commands scheduled in the duration.
+// Returns messages posted by the bot while handling the commands.
+func (h *Harness) Advance(d time.Duration) []*Post {
+	h.t.Helper()
+
+	n := len(h.service.Posts())
+
+	h.clock.Advance(d)
+	h.wait()
+
+	return h.service.Posts()[n:]
+}
+
+// Disconnect disconnects the service, and waits until the bot handles it.
+func (h *Harness) Disconnect() {
+	h.t.Helper()
+
+	h.service.Disconnect()
+	h.wait()
+}
+
+// Reconnect reconnects the service, and waits until the bot handles it.
+func (h *Harness) Reconnect() {
+	h.t.Helper()
+
+	h.service.Reconnect()
+	h.wait()
+}
+
+// FailDelivery fails delivering the message, and waits until the bot handles it.
+func (h *Harness) FailDelivery(channelID, text string, err error) {
+	h.t.Helper()
+
+	h.service.FailDelivery(channelID, text, err)
+	h.wait()
+}
+
+// AddReaction adds a reaction of the user to the message, and waits until the bot handles it.
+// Returns messages posted by the bot while handling the reaction.
+func (h *Harness) AddReaction(ref plugin.MessageRef, userID, emoji string) []*Post {
+	h.t.Helper()
+
+	n := len(h.service.Posts())
+
+	h.service.AddReaction(ref, userID, emoji)
+	h.wait()
+
+	return h.service.Posts()[n:]
+}
+
+// RemoveReaction removes a reaction of the user from the message, and waits until the bot handles it.
+// Returns messages posted by the bot while handling the reaction.
+func (h *Harness) RemoveReaction(ref plugin.MessageRef, userID, emoji string) []*Post {
+	h.t.Helper()
+
+	n := len(h.service.Posts())
+
+	h.service.RemoveReaction(ref, userID, emoji)
+	h.wait()
+
+	return h.service.Posts()[n:]
+}
+
+// Interact clicks the button or selects the option of the action in the message as the user,
+// and waits until the bot handles it.
+// Returns messages posted by the bot while handling the interaction.
+// Messages updated by the bot are not returned, see Posts.
+func (h *Harness) Interact(ref plugin.MessageRef, userID, actionID, value string) []*Post {
+	h.t.Helper()
+
+	n := len(h.service.Posts())
+
+	h.service.Interact(ref, userID, actionID, value)
+	h.wait()
+
+	return h.service.Posts()[n:]
+}
+
+// Reactions returns all the reactions added by the bot.
+func (h *Harness) Reactions() []*Reaction {
+	return h.service.Reactions()
+}
+
+// Posts returns all the messages posted by the bot.
+func (h *Harness) Posts() []*Post {
+	return h.service.Posts()
+}
@@ -0,0 +1,587 @@
+package bottest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kechako/gopher-bot/v2/internal/local"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+	"github.com/kechako/gopher-bot/v2/service"
+)
+
+// BotUserID is the user ID of the bot on the Service.
+const BotUserID = "bot"
+
+// ErrMessageNotFound is the error returned when the message to edit or delete is not posted by the bot.
+var ErrMessageNotFound = errors.New("message not found")
+
+// Post represents a message posted by the bot.
+type Post struct {
+	// ID is the ID of the message, numbered from "1" in the order of posts.
+	ID string
+	// ChannelID is the ID of the channel that the message was posted to.
+	ChannelID string
+	// ThreadID is the ID of the thread that the message was posted to.
+	// It is empty if the message is not posted to a thread.
+	ThreadID string
+	// EphemeralTo is the ID of the user that the message is only visible to.
+	// It is empty if the message is visible to everyone.
+	EphemeralTo string
+	// MentionTo is the ID of the user that the message mentions to.
+	// It is empty if the message is not a mention.
+	MentionTo string
+	// Text is the text of the message. It is the plain text of Rich if Rich is not nil.
+	Text string
+	// Rich is the rich message. It is nil if the message is not a rich message.
+	Rich *rich.Message
+	// File is the uploaded file. It is nil if the message is not an upload.
+	// Text is the comment of the file.
+	File *File
+	// Edited indicates the text has been edited.
+	Edited bool
+	// Deleted indicates the message has been deleted.
+	Deleted bool
+}
+
+// String returns the text of the post, prefixed with the mention if any.
+func (p *Post) String() string {
+	if p.MentionTo == "" {
+		return p.Text
+	}
+	return "@" + p.MentionTo + " " + p.Text
+}
+
+// Reaction represents a reaction added by the bot.
+type Reaction struct {
+	// Target is the reference to the message that the reaction is added to.
+	Target plugin.MessageRef
+	// Emoji is the emoji of the reaction.
+	Emoji string
+}
+
+// Texts returns the strings of the posts.
+func Texts(posts []*Post) []string {
+	texts := make([]string, len(posts))
+	for i, p := range posts {
+		texts[i] = p.String()
+	}
+
+	return texts
+}
+
+// Service is an in-memory service.Service for tests.
+// Messages are injected by Send, and messages posted by the bot are recorded in order.
+//
+// Mentions in the injected messages are written as @<user>.
+type Service struct {
+	ch   chan *service.Event
+	exit context.CancelFunc
+	ctx  context.Context
+
+	posts     []*Post
+	reactions []*Reaction
+	commands  []*plugin.Help
+	mux       sync.Mutex
+
+	ephemeralHelp bool
+
+	pending sync.WaitGroup
+	started chan struct{}
+
+	l *slog.Logger
+}
+
+var (
+	_ service.Service          = (*Service)(nil)
+	_ service.CommandRegistrar = (*Service)(nil)
+	_ local.Service            = (*Service)(nil)
+	_ service.ContextPoster    = (*Service)(nil)
+	_ service.Editor           = (*Service)(nil)
+	_ service.Reactor          = (*Service)(nil)
+	_ service.ThreadPoster     = (*Service)(nil)
+	_ service.DirectMessenger  = (*Service)(nil)
+	_ service.RichPoster       = (*Service)(nil)
+	_ service.Uploader         = (*Service)(nil)
+	_ service.EphemeralHelper  = (*Service)(nil)
+)
+
+// NewService returns a new *Service.
+func NewService() *Service {
+	return &Service{
+		started: make(chan struct{}),
+		l:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+// Start implements the service.Service interface.
+func (s *Service) Start(ctx context.Context) (<-chan *service.Event, error) {
+	s.ch = make(chan *service.Event)
+	s.ctx, s.exit = context.WithCancel(ctx)
+
+	s.send(&service.Event{
+		Type: service.ConnectedEvent,
+		Data: local.NewHello(s, s.l),
+	})
+	close(s.started)
+
+	return s.ch, nil
+}
+
+// send sends the event to the bot in a new goroutine,
+// and counts the event as pending until the bot finishes handling it.
+func (s *Service) send(event *service.Event) {
+	s.pending.Add(1)
+	event.Done = s.pending.Done
+
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			s.pending.Done()
+		case s.ch <- event:
+		}
+	}()
+}
+
+// Send injects a message posted by the user to the channel.
+func (s *Service) Send(channelID, userID, text string) {
+	s.send(&service.Event{
+		Type: service.MessageEvent,
+		Data: local.NewMessage(s, channelID, "", userID, text),
+	})
+}
+
+// SendToThread injects a message posted by the user to the thread of the channel.
+func (s *Service) SendToThread(channelID, threadID, userID, text string) {
+	s.send(&service.Event{
+		Type: service.MessageEvent,
+		Data: local.NewMessage(s, channelID, threadID, userID, text),
+	})
+}
+
+// SendWithFiles injects a message with the files attached, posted by the user to the channel.
+func (s *Service) SendWithFiles(channelID, userID, text string, files ...*File) {
+	s.send(&service.Event{
+		Type: service.MessageEvent,
+		Data: local.NewMessage(s, channelID, "", userID, text, attachments(files)...),
+	})
+}
+
+// EditMessage injects a message edited by the user in the channel. text is the edited text.
+func (s *Service) EditMessage(channelID, userID, text string) {
+	s.send(&service.Event{
+		Type: service.MessageEditedEvent,
+		Data: local.NewMessage(s, channelID, "", userID, text),
+	})
+}
+
+// DeleteMessage injects a message deleted by the user.
+func (s *Service) DeleteMessage(ref plugin.MessageRef) {
+	s.send(&service.Event{
+		Type: service.MessageDeletedEvent,
+		Data: &ref,
+	})
+}
+
+// Disconnect injects an event that the service has disconnected.
+func (s *Service) Disconnect() {
+	s.send(&service.Event{
+		Type: service.DisconnectedEvent,
+	})
+}
+
+// Reconnect injects an event that the service has connected again.
+func (s *Service) Reconnect() {
+	s.send(&service.Event{
+		Type: service.ConnectedEvent,
+		Data: local.NewHello(s, s.l),
+	})
+}
+
+// FailDelivery injects an event that the service has given up delivering the message.
+func (s *Service) FailDelivery(channelID, text string, err error) {
+	s.send(&service.Event{
+		Type: service.DeliveryFailedEvent,
+		Data: &plugin.DeliveryFailure{
+			ChannelID: channelID,
+			Text:      text,
+			Err:       err,
+		},
+	})
+}
+
+// AddReaction injects a reaction added by the user to the message.
+func (s *Service) AddReaction(ref plugin.MessageRef, userID, emoji string) {
+	s.sendReaction(ref, userID, emoji, true)
+}
+
+// RemoveReaction injects a reaction removed by the user from the message.
+func (s *Service) RemoveReaction(ref plugin.MessageRef, userID, emoji string) {
+	s.sendReaction(ref, userID, emoji, false)
+}
+
+func (s *Service) sendReaction(ref plugin.MessageRef, userID, emoji string, added bool) {
+	s.send(&service.Event{
+		Type: service.ReactionEvent,
+		Data: local.NewReaction(userID, emoji, ref, added),
+	})
+}
+
+// Interact injects an interaction of the user with the button or the select menu
+// of the action in the message. value is the value of the button or the selected option.
+func (s *Service) Interact(ref plugin.MessageRef, userID, actionID, value string) {
+	s.send(&service.Event{
+		Type: service.InteractionEvent,
+		Data: local.NewInteraction(s, userID, actionID, value, ref),
+	})
+}
+
+// Wait waits until the service is started and the bot finishes handling
+// all the injected events, including commands processed by plugins while handling them.
+func (s *Service) Wait(timeout time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		<-s.started
+		s.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return errors.New("timeout waiting for the bot to handle events")
+	}
+}
+
+// Posts returns all the messages posted by the bot.
+func (s *Service) Posts() []*Post {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	posts := make([]*Post, len(s.posts))
+	copy(posts, s.posts)
+
+	return posts
+}
+
+// Reactions returns all the reactions added by the bot.
+func (s *Service) Reactions() []*Reaction {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	reactions := make([]*Reaction, len(s.reactions))
+	copy(reactions, s.reactions)
+
+	return reactions
+}
+
+// Commands returns the helps of the plugins registered by the bot.
+func (s *Service) Commands() []*plugin.Help {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	commands := make([]*plugin.Help, len(s.commands))
+	copy(commands, s.commands)
+
+	return commands
+}
+
+// record records the post, and returns the reference to it.
+func (s *Service) record(p *Post) plugin.MessageRef {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	p.ID = strconv.Itoa(len(s.posts) + 1)
+	s.posts = append(s.posts, p)
+
+	return plugin.MessageRef{
+		ChannelID: p.ChannelID,
+		MessageID: p.ID,
+	}
+}
+
+// Close implements the service.Service interface.
+func (s *Service) Close() error {
+	if s.exit != nil {
+		s.exit()
+	}
+	return nil
+}
+
+// UserID implements the service.Service interface.
+func (s *Service) UserID() string {
+	return BotUserID
+}
+
+// Post implements the service.Service interface.
+func (s *Service) Post(channelID, text string) {
+	s.record(&Post{
+		ChannelID: channelID,
+		Text:      text,
+	})
+}
+
+// Mention implements the service.Service interface.
+func (s *Service) Mention(channelID, userID, text string) {
+	s.record(&Post{
+		ChannelID: channelID,
+		MentionTo: userID,
+		Text:      text,
+	})
+}
+
+// PostToThread implements the service.ThreadPoster interface.
+func (s *Service) PostToThread(channelID, threadID, text string) {
+	s.record(&Post{
+		ChannelID: channelID,
+		ThreadID:  threadID,
+		Text:      text,
+	})
+}
+
+// MentionToThread posts a new message that mentions to the user to the thread of the channel.
+func (s *Service) MentionToThread(channelID, threadID, userID, text string) {
+	s.record(&Post{
+		ChannelID: channelID,
+		ThreadID:  threadID,
+		MentionTo: userID,
+		Text:      text,
+	})
+}
+
+// DirectMessage implements the service.DirectMessenger interface.
+// The message is posted to the channel DirectChannel(userID).
+func (s *Service) DirectMessage(userID, text string) error {
+	s.Post(DirectChannel(userID), text)
+	return nil
+}
+
+// PostEphemeral posts a new message to the thread of the channel, that is visible only to the user.
+func (s *Service) PostEphemeral(channelID, threadID, userID, text string) {
+	s.record(&Post{
+		ChannelID:   channelID,
+		ThreadID:    threadID,
+		EphemeralTo: userID,
+		Text:        text,
+	})
+}
+
+// DirectChannel returns the ID of the direct message channel with the user.
+// Messages sent to the channel are direct messages.
+func DirectChannel(userID string) string {
+	return local.DirectChannel(userID)
+}
+
+// PostContext implements the service.ContextPoster interface.
+func (s *Service) PostContext(ctx context.Context, channelID, text string) (plugin.MessageRef, error) {
+	return s.PostToThreadContext(ctx, channelID, "", text)
+}
+
+// MentionContext implements the service.ContextPoster interface.
+func (s *Service) MentionContext(ctx context.Context, channelID, userID, text string) (plugin.MessageRef, error) {
+	return s.MentionToThreadContext(ctx, channelID, "", userID, text)
+}
+
+// PostToThreadContext posts a new message to the thread of the channel.
+func (s *Service) PostToThreadContext(ctx context.Context, channelID, threadID, text string) (plugin.MessageRef, error) {
+	if err := ctx.Err(); err != nil {
+		return plugin.MessageRef{}, err
+	}
+
+	return s.record(&Post{
+		ChannelID: channelID,
+		ThreadID:  threadID,
+		Text:      text,
+	}), nil
+}
+
+// MentionToThreadContext posts a new message that mentions to the user to the thread of the channel.
+func (s *Service) MentionToThreadContext(ctx context.Context, channelID, threadID, userID, text string) (plugin.MessageRef, error) {
+	if err := ctx.Err(); err != nil {
+		return plugin.MessageRef{}, err
+	}
+
+	return s.record(&Post{
+		ChannelID: channelID,
+		ThreadID:  threadID,
+		MentionTo: userID,
+		Text:      text,
+	}), nil
+}
+
+// PostRich implements the service.RichPoster interface.
+func (s *Service) PostRich(channelID string, msg *rich.Message) {
+	s.PostRichToThread(channelID, "", msg)
+}
+
+// PostRichContext implements the service.RichPoster interface.
+func (s *Service) PostRichContext(ctx context.Context, channelID string, msg *rich.Message) (plugin.MessageRef, error) {
+	if err := ctx.Err(); err != nil {
+		return plugin.MessageRef{}, err
+	}
+
+	return s.record(&Post{
+		ChannelID: channelID,
+		Text:      msg.PlainText(),
+		Rich:      msg,
+	}), nil
+}
+
+// PostRichToThread posts a new rich message to the thread of the channel.
+func (s *Service) PostRichToThread(channelID, threadID string, msg *rich.Message) {
+	s.record(&Post{
+		ChannelID: channelID,
+		ThreadID:  threadID,
+		Text:      msg.PlainText(),
+		Rich:      msg,
+	})
+}
+
+// Edit implements the service.Editor interface.
+func (s *Service) Edit(ref plugin.MessageRef, text string) error {
+	return s.update(ref, func(p *Post) {
+		p.Text = text
+		p.Edited = true
+	})
+}
+
+// EditRich replaces the message with the rich message.
+func (s *Service) EditRich(ref plugin.MessageRef, msg *rich.Message) error {
+	return s.update(ref, func(p *Post) {
+		p.Text = msg.PlainText()
+		p.Rich = msg
+		p.Edited = true
+	})
+}
+
+// Delete implements the service.Editor interface.
+func (s *Service) Delete(ref plugin.MessageRef) error {
+	return s.update(ref, func(p *Post) {
+		p.Deleted = true
+	})
+}
+
+// update replaces the post of the ref with a copy updated by f.
+func (s *Service) update(ref plugin.MessageRef, f func(p *Post)) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for i, p := range s.posts {
+		if p.ChannelID == ref.ChannelID && p.ID == ref.MessageID && !p.Deleted {
+			updated := *p
+			f(&updated)
+			s.posts[i] = &updated
+			return nil
+		}
+	}
+
+	return ErrMessageNotFound
+}
+
+// React implements the service.Reactor interface.
+func (s *Service) React(ref plugin.MessageRef, emoji string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.reactions = append(s.reactions, &Reaction{
+		Target: ref,
+		Emoji:  emoji,
+	})
+
+	return nil
+}
+
+// Upload implements the service.Uploader interface.
+func (s *Service) Upload(channelID, name string, r io.Reader, comment string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.record(&Post{
+		ChannelID: channelID,
+		Text:      comment,
+		File: &File{
+			Name: name,
+			Data: data,
+		},
+	})
+
+	return nil
+}
+
+// RegisterCommands implements the service.CommandRegistrar interface.
+func (s *Service) RegisterCommands(ctx context.Context, helps []*plugin.Help) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.commands = helps
+
+	return nil
+}
+
+// ProcessCommmand processes the specified command on the channel.
+func (s *Service) ProcessCommand(channelID string, command string) {
+	s.ProcessThreadCommand(channelID, "", command)
+}
+
+// ProcessThreadCommand processes the specified command on the thread of the channel.
+func (s *Service) ProcessThreadCommand(channelID, threadID, command string) {
+	s.send(&service.Event{
+		Type: service.MessageEvent,
+		Data: local.NewCommandMessage(s, channelID, threadID, command),
+	})
+}
+
+// ProcessCommandContext processes the specified command on the channel.
+// The command is always passed to the bot unless ctx is done.
+func (s *Service) ProcessCommandContext(ctx context.Context, channelID string, command string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.ProcessCommand(channelID, command)
+	return nil
+}
+
+// Channel returns a channel of specified channelID.
+func (s *Service) Channel(channelID string) plugin.Channel {
+	return local.NewChannel(channelID, channelID)
+}
+
+// User returns a user of specified userID.
+func (s *Service) User(userID string) plugin.User {
+	return local.NewUser(userID, userID == BotUserID)
+}
+
+// SetEphemeralHelp implements the service.EphemeralHelper interface.
+func (s *Service) SetEphemeralHelp(ephemeral bool) {
+	s.ephemeralHelp = ephemeral
+}
+
+// EphemeralHelp implements the local.Service interface.
+// It returns whether help messages are posted ephemerally.
+func (s *Service) EphemeralHelp() bool {
+	return s.ephemeralHelp
+}
+
+// EscapeHelp implements the service.Service interface.
+func (s *Service) EscapeHelp(help string) string {
+	var escaped strings.Builder
+
+	escaped.WriteString("```\n")
+	escaped.WriteString(help)
+	if !strings.HasSuffix(help, "\n") {
+		escaped.WriteRune('\n')
+	}
+	escaped.WriteString("```")
+
+	return escaped.String()
+}
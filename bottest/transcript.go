@@ -0,0 +1,137 @@
+package bottest
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update golden transcript files")
+
+// RunTranscript runs the conversation in the transcript file, and compares
+// messages posted by the bot with the replies in the file.
+// If the -update flag is set, the replies in the file are updated instead.
+// The flag is defined in the test binaries of the packages that import bottest:
+//
+//	go test ./location -update
+//
+// A transcript is a plain text file of the following lines:
+//
+//	# comment
+//	> alice: loc list
+//	< bot: tokyo [35.700001, 139.699997]
+//	|   a continuation line of the reply
+//	/channel random
+//	/advance 1h
+//
+// Lines starting with ">" are messages posted by the user to the current channel.
+// Lines starting with "<" are replies of the bot, followed by "|" lines if the reply
+// has multiple lines. If a reply is posted to a channel other than the current one,
+// the channel is written as "< bot #channel: ...".
+// "/channel" changes the current channel, which is "general" by default.
+// "/advance" advances the clock by the duration, and the replies that follow are
+// posted by the commands scheduled in the duration.
+func (h *Harness) RunTranscript(path string) {
+	h.t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		h.t.Fatal("failed to read transcript: ", err)
+	}
+
+	want := string(data)
+	got, err := h.runTranscript(want)
+	if err != nil {
+		h.t.Fatalf("failed to run transcript %s: %v", path, err)
+	}
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			h.t.Fatal("failed to update transcript: ", err)
+		}
+		return
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		h.t.Errorf("transcript %s differs: (-got +want)\n%s", path, diff)
+	}
+}
+
+func (h *Harness) runTranscript(transcript string) (string, error) {
+	var out strings.Builder
+
+	channelID := "general"
+
+	scanner := bufio.NewScanner(strings.NewReader(transcript))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "<"), strings.HasPrefix(line, "|"):
+			// replies are rendered from the actual posts
+			continue
+		case strings.HasPrefix(line, ">"):
+			userID, text, ok := strings.Cut(strings.TrimSpace(line[1:]), ": ")
+			if !ok {
+				return "", fmt.Errorf("line %d: invalid message: %s", n, line)
+			}
+
+			out.WriteString(line)
+			out.WriteString("\n")
+			writeReplies(&out, channelID, h.Send(channelID, userID, text))
+		case strings.HasPrefix(line, "/channel "):
+			channelID = strings.TrimPrefix(strings.TrimSpace(line[len("/channel "):]), "#")
+
+			out.WriteString(line)
+			out.WriteString("\n")
+		case strings.HasPrefix(line, "/advance "):
+			d, err := time.ParseDuration(strings.TrimSpace(line[len("/advance "):]))
+			if err != nil {
+				return "", fmt.Errorf("line %d: invalid duration: %w", n, err)
+			}
+
+			out.WriteString(line)
+			out.WriteString("\n")
+			writeReplies(&out, channelID, h.Advance(d))
+		case line == "", strings.HasPrefix(line, "#"):
+			out.WriteString(line)
+			out.WriteString("\n")
+		default:
+			return "", fmt.Errorf("line %d: unknown line: %s", n, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+func writeReplies(out *strings.Builder, channelID string, posts []*Post) {
+	for _, p := range posts {
+		out.WriteString("< ")
+		out.WriteString(BotUserID)
+		if p.ChannelID != channelID {
+			out.WriteString(" #")
+			out.WriteString(p.ChannelID)
+		}
+		out.WriteString(":")
+
+		for i, line := range strings.Split(p.String(), "\n") {
+			if i > 0 {
+				out.WriteString("\n|")
+			}
+			if line != "" {
+				out.WriteString(" ")
+				out.WriteString(line)
+			}
+		}
+		out.WriteString("\n")
+	}
+}
@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// timeout returns the timeout for the hook of the plugin.
+func (b *Bot) timeout(p plugin.Plugin, hook plugin.Hook) time.Duration {
+	if tp, ok := p.(plugin.TimeoutProvider); ok {
+		if d := tp.Timeout(hook); d > 0 {
+			return d
+		}
+	}
+
+	if d, ok := b.timeouts[hook]; ok && d > 0 {
+		return d
+	}
+
+	return defaultTimeout
+}
+
+// callPlugin calls h wrapped with the middlewares in a new goroutine and waits for it
+// until the timeout of the hook.
+// When the timeout expires, the context passed to h is canceled and the goroutine
+// is tracked as an abandoned call until h returns.
+// Returns true if h has returned before the timeout.
+func (b *Bot) callPlugin(ctx context.Context, req *Request, h Handler) bool {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout(req.Plugin, req.Hook))
+
+	h = chain(h, b.middlewares)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h(ctx, req)
+	}()
+
+	select {
+	case <-done:
+		cancel()
+		return true
+	case <-ctx.Done():
+		cancel()
+		name := pluginName(req.Plugin)
+		b.l.Error(fmt.Sprintf("abort plugin.%s()", req.Hook), slog.Any("err", ctx.Err()), slog.String("plugin", name))
+		b.calls.abandon(name, req.Hook, done)
+		return false
+	}
+}
+
+func pluginName(p plugin.Plugin) string {
+	return fmt.Sprintf("%T", p)
+}
+
+// abandonedCall represents a plugin call that is still running after its timeout.
+type abandonedCall struct {
+	plugin string
+	hook   plugin.Hook
+	since  time.Time
+}
+
+// callTracker tracks abandoned plugin calls.
+type callTracker struct {
+	calls map[*abandonedCall]struct{}
+	mux   sync.Mutex
+}
+
+// abandon tracks the call until done is closed.
+func (t *callTracker) abandon(name string, hook plugin.Hook, done <-chan struct{}) {
+	call := &abandonedCall{
+		plugin: name,
+		hook:   hook,
+		since:  time.Now(),
+	}
+
+	t.mux.Lock()
+	if t.calls == nil {
+		t.calls = make(map[*abandonedCall]struct{})
+	}
+	t.calls[call] = struct{}{}
+	t.mux.Unlock()
+
+	go func() {
+		<-done
+
+		t.mux.Lock()
+		delete(t.calls, call)
+		t.mux.Unlock()
+	}()
+}
+
+// leaked returns the calls that are still running.
+func (t *callTracker) leaked() []*abandonedCall {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	calls := make([]*abandonedCall, 0, len(t.calls))
+	for call := range t.calls {
+		calls = append(calls, call)
+	}
+
+	return calls
+}
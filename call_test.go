@@ -0,0 +1,136 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+// timeoutPlugin is a plugin that provides the timeouts of the hooks.
+type timeoutPlugin struct {
+	timeouts map[plugin.Hook]time.Duration
+}
+
+func (p *timeoutPlugin) Hello(ctx context.Context, hello plugin.Hello)    {}
+func (p *timeoutPlugin) DoAction(ctx context.Context, msg plugin.Message) {}
+func (p *timeoutPlugin) Help(ctx context.Context) *plugin.Help            { return nil }
+
+func (p *timeoutPlugin) Timeout(hook plugin.Hook) time.Duration {
+	return p.timeouts[hook]
+}
+
+func newTestBot(t *testing.T, w io.Writer, opts ...Option) *Bot {
+	t.Helper()
+
+	opts = append([]Option{
+		WithDatabaseDir(t.TempDir()),
+		WithLogger(slog.New(slog.NewTextHandler(w, nil))),
+	}, opts...)
+	b, err := New(nil, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+var timeoutTests = map[string]struct {
+	opts     []Option
+	timeouts map[plugin.Hook]time.Duration
+	want     time.Duration
+}{
+	"default": {
+		want: defaultTimeout,
+	},
+	"option": {
+		opts: []Option{WithTimeout(plugin.DoActionHook, time.Minute)},
+		want: time.Minute,
+	},
+	"option of another hook": {
+		opts: []Option{WithTimeout(plugin.HelpHook, time.Minute)},
+		want: defaultTimeout,
+	},
+	"provider": {
+		opts:     []Option{WithTimeout(plugin.DoActionHook, time.Minute)},
+		timeouts: map[plugin.Hook]time.Duration{plugin.DoActionHook: time.Hour},
+		want:     time.Hour,
+	},
+	"provider returns zero": {
+		opts:     []Option{WithTimeout(plugin.DoActionHook, time.Minute)},
+		timeouts: map[plugin.Hook]time.Duration{plugin.HelpHook: time.Hour},
+		want:     time.Minute,
+	},
+}
+
+func Test_Bot_timeout(t *testing.T) {
+	for name, tt := range timeoutTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			b := newTestBot(t, io.Discard, tt.opts...)
+			t.Cleanup(func() { b.Close() })
+
+			p := &timeoutPlugin{timeouts: tt.timeouts}
+			if d := b.timeout(p, plugin.DoActionHook); d != tt.want {
+				t.Errorf("Bot.timeout() => %v, want %v", d, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Bot_callPlugin_abandon(t *testing.T) {
+	var buf bytes.Buffer
+	b := newTestBot(t, &buf, WithTimeout(plugin.DoActionHook, 10*time.Millisecond))
+
+	release := make(chan struct{})
+	canceled := make(chan struct{})
+	req := &Request{
+		Hook:   plugin.DoActionHook,
+		Plugin: &timeoutPlugin{},
+	}
+	ok := b.callPlugin(context.Background(), req, func(ctx context.Context, req *Request) {
+		<-ctx.Done()
+		close(canceled)
+		<-release
+	})
+	if ok {
+		t.Error("Bot.callPlugin must return false when the plugin does not return before the timeout")
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the context passed to the plugin is not canceled after the timeout")
+	}
+
+	calls := b.calls.leaked()
+	if len(calls) != 1 {
+		t.Fatalf("Bot.calls.leaked() returns %d calls, want 1", len(calls))
+	}
+	if calls[0].plugin != "*bot.timeoutPlugin" || calls[0].hook != plugin.DoActionHook {
+		t.Errorf("the abandoned call is %s %s, want *bot.timeoutPlugin DoAction", calls[0].plugin, calls[0].hook)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	log := buf.String()
+	if !strings.Contains(log, "plugin call is still running after timeout") || !strings.Contains(log, "plugin=*bot.timeoutPlugin") {
+		t.Errorf("Bot.Close must report the leaked call, got %q", log)
+	}
+
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for len(b.calls.leaked()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the call is still tracked after the plugin has returned")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -3,26 +3,49 @@ package cron
 
 import (
 	"context"
+	"errors"
 	"log/slog"
-	"strings"
 
 	"github.com/kechako/gopher-bot/v2/internal/cron"
 	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
 )
 
 const commandName = "cron"
 
 type cronPlugin struct {
-	bot  plugin.Bot
-	cron *cron.Cron
-	l    *slog.Logger
+	bot    plugin.Bot
+	cron   *cron.Cron
+	clock  Clock
+	router *command.Router
+	l      *slog.Logger
 }
 
 var _ plugin.Plugin = (*cronPlugin)(nil)
 
+// Clock is the interface that runs scheduled commands.
+// *cron.Cron of github.com/robfig/cron/v3 implements it.
+type Clock = cron.Clock
+
+// Option is an option of the cron plugin.
+type Option func(p *cronPlugin)
+
+// WithClock sets the clock used to schedule commands.
+// It is mainly used in tests to control the time.
+func WithClock(clock Clock) Option {
+	return func(p *cronPlugin) {
+		p.clock = clock
+	}
+}
+
 // New returns a new plugin.Plugin that manages crons.
-func New() plugin.Plugin {
-	return &cronPlugin{}
+func New(opts ...Option) plugin.Plugin {
+	p := &cronPlugin{}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 func (p *cronPlugin) Close() error {
@@ -31,7 +54,8 @@ func (p *cronPlugin) Close() error {
 
 func (p *cronPlugin) Hello(ctx context.Context, hello plugin.Hello) {
 	p.bot = hello.Bot()
-	p.cron = cron.New(&cronBot{plugin: p})
+	p.cron = cron.New(&cronBot{plugin: p}, p.clock)
+	p.router = command.New(commandName, p.cron.Commands()...)
 	p.l = hello.Bot().Logger().With(slog.String("plugin", "cron"))
 
 	if err := p.cron.Start(ctx); err != nil {
@@ -40,19 +64,17 @@ func (p *cronPlugin) Hello(ctx context.Context, hello plugin.Hello) {
 }
 
 func (p *cronPlugin) DoAction(ctx context.Context, msg plugin.Message) {
-	params := strings.Fields(msg.Text())
-	if len(params) == 0 || params[0] != commandName {
-		return
-	}
-
-	retMsg, err := p.cron.Execute(ctx, params[1:], msg.ChannelID())
+	retMsg, err := p.router.Execute(ctx, msg)
 	if err != nil {
-		if err == cron.ErrInvalidSyntax {
+		switch {
+		case errors.Is(err, command.ErrNotMatched):
+		case errors.Is(err, command.ErrInvalidSyntax):
 			msg.PostHelp(p.Help(ctx))
-			return
+		case errors.Is(err, command.ErrDirectOnly):
+			command.PostDirectOnly(msg)
+		default:
+			p.l.Error("failed to do plugin action", slog.Any("err", err))
 		}
-
-		p.l.Error("failed to do plugin action", slog.Any("err", err))
 		return
 	}
 
@@ -63,7 +85,7 @@ func (p *cronPlugin) Help(ctx context.Context) *plugin.Help {
 	return &plugin.Help{
 		Name:        "cron",
 		Description: "Management command schedules.",
-		Commands:    p.cron.HelpCommands(commandName),
+		Commands:    p.router.HelpCommands(),
 	}
 }
 
@@ -73,7 +95,12 @@ type cronBot struct {
 
 var _ cron.Bot = (*cronBot)(nil)
 
-func (bot *cronBot) ProcessCommand(channelID, command string) {
+func (bot *cronBot) ProcessCommand(channelID, threadID, command string) {
+	if p, ok := bot.plugin.bot.(plugin.ThreadPoster); ok {
+		p.ProcessThreadCommand(channelID, threadID, command)
+		return
+	}
+
 	bot.plugin.bot.ProcessCommand(channelID, command)
 }
 
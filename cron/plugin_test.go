@@ -0,0 +1,119 @@
+package cron_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kechako/gopher-bot/v2/bottest"
+	"github.com/kechako/gopher-bot/v2/cron"
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+type pingPlugin struct{}
+
+func (p *pingPlugin) Hello(ctx context.Context, hello plugin.Hello) {}
+
+func (p *pingPlugin) DoAction(ctx context.Context, msg plugin.Message) {
+	if msg.Text() == "ping" {
+		msg.Post("pong")
+	}
+}
+
+func (p *pingPlugin) Help(ctx context.Context) *plugin.Help {
+	return &plugin.Help{Name: "ping"}
+}
+
+func Test_Plugin(t *testing.T) {
+	h := bottest.New(t)
+	h.AddPlugin(cron.New(cron.WithClock(h.Clock())), &pingPlugin{})
+	h.Start()
+
+	tests := []struct {
+		text    string
+		advance time.Duration
+		posts   []string
+	}{
+		{
+			text:  "cron list",
+			posts: []string{"Schedule list is empty."},
+		},
+		{
+			text:  "cron add hourly 0 * * * * ping",
+			posts: []string{"Success to add a new schedule : hourly [0 * * * *, ping, general]"},
+		},
+		{
+			text:  "cron add hourly 0 * * * * ping",
+			posts: []string{"hourly already exists"},
+		},
+		{
+			advance: 30 * time.Minute,
+			posts:   []string{},
+		},
+		{
+			advance: 2 * time.Hour,
+			posts:   []string{"pong", "pong"},
+		},
+		{
+			text:  "cron list",
+			posts: []string{"hourly : 0 * * * * ping [general]"},
+		},
+		{
+			text:  "cron remove hourly",
+			posts: []string{"Success to remove a schedule : hourly"},
+		},
+		{
+			advance: 2 * time.Hour,
+			posts:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		var posts []*bottest.Post
+		if tt.advance > 0 {
+			posts = h.Advance(tt.advance)
+		} else {
+			posts = h.Send("general", "alice", tt.text)
+		}
+
+		if diff := cmp.Diff(bottest.Texts(posts), tt.posts); diff != "" {
+			t.Errorf("failed to handle %q (advance %s): (-got +want)\n%s", tt.text, tt.advance, diff)
+		}
+	}
+}
+
+func Test_Plugin_transcript(t *testing.T) {
+	h := bottest.New(t)
+	h.AddPlugin(cron.New(cron.WithClock(h.Clock())), &pingPlugin{})
+	h.Start()
+
+	h.RunTranscript("testdata/cron.txt")
+}
+
+func Test_Plugin_thread(t *testing.T) {
+	h := bottest.New(t)
+	h.AddPlugin(cron.New(cron.WithClock(h.Clock())), &pingPlugin{})
+	h.Start()
+
+	h.SendToThread("general", "T1", "alice", "cron add hourly 0 * * * * ping")
+	h.Send("general", "alice", "cron add --thread=T2 daily 30 0 * * * ping")
+	h.Advance(30 * time.Minute)
+	h.Advance(30 * time.Minute)
+
+	var got []*bottest.Post
+	for _, p := range h.Posts() {
+		got = append(got, &bottest.Post{ChannelID: p.ChannelID, ThreadID: p.ThreadID, Text: p.Text})
+	}
+
+	want := []*bottest.Post{
+		{ChannelID: "general", ThreadID: "T1", Text: "Success to add a new schedule : hourly [0 * * * *, ping, general (thread T1)]"},
+		{ChannelID: "general", Text: "Success to add a new schedule : daily [30 0 * * *, ping, general (thread T2)]"},
+		{ChannelID: "general", ThreadID: "T2", Text: "pong"},
+		{ChannelID: "general", ThreadID: "T1", Text: "pong"},
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("posts differ: (-got +want)\n%s", diff)
+	}
+}
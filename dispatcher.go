@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWorkers      = 4
+	defaultQueueSize    = 64
+	defaultQueueTimeout = 3 * time.Second
+)
+
+// job is a unit of work processed by a dispatcher worker.
+type job struct {
+	ctx context.Context
+	fn  func(ctx context.Context)
+	// done is called after fn, or instead of fn if the job is skipped because ctx is done.
+	done func()
+}
+
+// dispatcher dispatches jobs to a bounded pool of workers.
+// Jobs with the same key are always processed by the same worker,
+// so that they are processed in the order they were dispatched.
+type dispatcher struct {
+	queues  []chan *job
+	timeout time.Duration
+	wg      sync.WaitGroup
+	l       *slog.Logger
+}
+
+// newDispatcher returns a new *dispatcher that has the specified number of workers.
+// Each worker has a queue that can hold queueSize jobs.
+// Dispatching to a full queue waits for timeout until the worker catches up.
+func newDispatcher(workers, queueSize int, timeout time.Duration, l *slog.Logger) *dispatcher {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	if timeout <= 0 {
+		timeout = defaultQueueTimeout
+	}
+
+	d := &dispatcher{
+		queues:  make([]chan *job, workers),
+		timeout: timeout,
+		l:       l,
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan *job, queueSize)
+	}
+
+	return d
+}
+
+// start starts the workers.
+func (d *dispatcher) start() {
+	for _, q := range d.queues {
+		d.wg.Add(1)
+		go d.work(q)
+	}
+}
+
+// stop stops the workers after all queued jobs are processed.
+func (d *dispatcher) stop() {
+	for _, q := range d.queues {
+		close(q)
+	}
+	d.wg.Wait()
+}
+
+func (d *dispatcher) work(q <-chan *job) {
+	defer d.wg.Done()
+
+	for j := range q {
+		d.run(j)
+	}
+}
+
+// run calls the function of the job unless its context is done, and then calls done.
+func (d *dispatcher) run(j *job) {
+	if j.done != nil {
+		defer j.done()
+	}
+
+	if j.ctx.Err() != nil {
+		return
+	}
+	j.fn(j.ctx)
+}
+
+// dispatch queues fn to the worker selected by the key.
+// done is called when the job is finished or skipped, if it is not nil.
+// If the queue of the worker is full, it blocks until the queue has space,
+// so that the caller stops reading events while the workers are busy.
+// Returns false and drops fn if the queue is still full after the timeout, or ctx is done.
+// done is not called for the dropped job, so the caller must clean up.
+func (d *dispatcher) dispatch(ctx context.Context, key string, fn func(ctx context.Context), done func()) bool {
+	q := d.queues[d.index(key)]
+	j := &job{ctx: ctx, fn: fn, done: done}
+
+	select {
+	case q <- j:
+		return true
+	default:
+	}
+
+	timer := time.NewTimer(d.timeout)
+	defer timer.Stop()
+
+	select {
+	case q <- j:
+		return true
+	case <-timer.C:
+		d.l.Warn("drop event, dispatch queue is full", slog.String("key", key), slog.Int("queue_size", cap(q)), slog.Duration("timeout", d.timeout))
+		return false
+	case <-ctx.Done():
+		d.l.Warn("drop event, dispatch is canceled", slog.String("key", key), slog.Any("err", ctx.Err()))
+		return false
+	}
+}
+
+func (d *dispatcher) index(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(d.queues)))
+}
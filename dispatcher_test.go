@@ -0,0 +1,142 @@
+package bot
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_dispatcher_order(t *testing.T) {
+	t.Parallel()
+
+	d := newDispatcher(4, 100, time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	d.start()
+
+	ctx := context.Background()
+
+	var mux sync.Mutex
+	got := make(map[string][]int)
+	for i := 0; i < 50; i++ {
+		for _, key := range []string{"C1", "C2", "C3"} {
+			i, key := i, key
+			d.dispatch(ctx, key, func(ctx context.Context) {
+				mux.Lock()
+				got[key] = append(got[key], i)
+				mux.Unlock()
+			}, nil)
+		}
+	}
+
+	d.stop()
+
+	var want []int
+	for i := 0; i < 50; i++ {
+		want = append(want, i)
+	}
+	for _, key := range []string{"C1", "C2", "C3"} {
+		if diff := cmp.Diff(got[key], want); diff != "" {
+			t.Errorf("jobs of %s are not processed in order: (-got +want)\n%s", key, diff)
+		}
+	}
+}
+
+// blockWorker dispatches a job that blocks the worker of the key until the returned function is called.
+func blockWorker(d *dispatcher, key string) func() {
+	block := make(chan struct{})
+	started := make(chan struct{})
+	d.dispatch(context.Background(), key, func(ctx context.Context) {
+		close(started)
+		<-block
+	}, nil)
+	<-started
+
+	return func() { close(block) }
+}
+
+func Test_dispatcher_backpressure(t *testing.T) {
+	t.Parallel()
+
+	d := newDispatcher(1, 1, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	d.start()
+
+	ctx := context.Background()
+
+	unblock := blockWorker(d, "C1")
+	if !d.dispatch(ctx, "C1", func(ctx context.Context) {}, nil) {
+		t.Error("dispatcher.dispatch must queue a job when the queue has space")
+	}
+
+	time.AfterFunc(50*time.Millisecond, unblock)
+
+	start := time.Now()
+	if !d.dispatch(ctx, "C1", func(ctx context.Context) {}, nil) {
+		t.Error("dispatcher.dispatch must wait for the worker when the queue is full")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("dispatcher.dispatch returned after %v, want to block until the worker catches up", elapsed)
+	}
+
+	d.stop()
+}
+
+func Test_dispatcher_drop(t *testing.T) {
+	t.Parallel()
+
+	d := newDispatcher(1, 1, 50*time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	d.start()
+
+	ctx := context.Background()
+
+	unblock := blockWorker(d, "C1")
+	if !d.dispatch(ctx, "C1", func(ctx context.Context) {}, nil) {
+		t.Error("dispatcher.dispatch must queue a job when the queue has space")
+	}
+
+	start := time.Now()
+	if d.dispatch(ctx, "C1", func(ctx context.Context) {}, nil) {
+		t.Error("dispatcher.dispatch must drop a job when the queue is full after the timeout")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("dispatcher.dispatch dropped the job after %v, want after the timeout", elapsed)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if d.dispatch(canceled, "C1", func(ctx context.Context) {}, nil) {
+		t.Error("dispatcher.dispatch must drop a job when the context is done")
+	}
+
+	unblock()
+	d.stop()
+}
+
+func Test_dispatcher_canceled(t *testing.T) {
+	t.Parallel()
+
+	d := newDispatcher(1, 1, time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	d.start()
+
+	unblock := blockWorker(d, "C1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var called, done bool
+	if !d.dispatch(ctx, "C1", func(ctx context.Context) { called = true }, func() { done = true }) {
+		t.Fatal("dispatcher.dispatch must queue a job when the queue has space")
+	}
+	cancel()
+
+	unblock()
+	d.stop()
+
+	if called {
+		t.Error("the job is run after the context is done")
+	}
+	if !done {
+		t.Error("done of the skipped job is not called")
+	}
+}
@@ -24,6 +24,12 @@ func (e *echo) DoAction(ctx context.Context, msg plugin.Message) {
 	msg.Post(msg.Text())
 }
 
+// MessageEdited implements the plugin.EditHandler interface.
+// It echoes edited messages again.
+func (e *echo) MessageEdited(ctx context.Context, msg plugin.Message) {
+	e.DoAction(ctx, msg)
+}
+
 func (e *echo) Help(ctx context.Context) *plugin.Help {
 	return &plugin.Help{
 		Name:        "echo",
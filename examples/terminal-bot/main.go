@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	bot "github.com/kechako/gopher-bot/v2"
+	"github.com/kechako/gopher-bot/v2/cron"
+	"github.com/kechako/gopher-bot/v2/location"
+	"github.com/kechako/gopher-bot/v2/service/terminal"
+)
+
+func main() {
+	var user string
+	var channel string
+	var dbDir string
+	flag.StringVar(&user, "user", "user", "User ID that posts messages.")
+	flag.StringVar(&channel, "channel", "general", "Channel ID that messages are posted to.")
+	flag.StringVar(&dbDir, "db", "", "Database directory.")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	service, err := terminal.New(&terminal.Config{
+		Logger:    logger,
+		UserID:    user,
+		ChannelID: channel,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := []bot.Option{bot.WithLogger(logger)}
+	if dbDir != "" {
+		opts = append(opts, bot.WithDatabaseDir(dbDir))
+	}
+
+	b, err := bot.New(service, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer b.Close()
+
+	b.AddPlugin(cron.New())
+	b.AddPlugin(location.NewPlugin())
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := b.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
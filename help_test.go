@@ -0,0 +1,86 @@
+package bot_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	bot "github.com/kechako/gopher-bot/v2"
+	"github.com/kechako/gopher-bot/v2/bottest"
+	"github.com/kechako/gopher-bot/v2/cron"
+	"github.com/kechako/gopher-bot/v2/location"
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+func Test_Bot_postHelp(t *testing.T) {
+	h := bottest.New(t)
+	h.AddPlugin(cron.New(cron.WithClock(h.Clock())), location.NewPlugin())
+	h.Start()
+
+	h.RunTranscript("testdata/help.txt")
+}
+
+func Test_Bot_WithEphemeralHelp(t *testing.T) {
+	h := bottest.New(t, bot.WithEphemeralHelp())
+	h.AddPlugin(cron.New(cron.WithClock(h.Clock())))
+	h.Start()
+
+	tests := map[string]struct {
+		text        string
+		ephemeralTo string
+	}{
+		"help":   {text: "@bot help", ephemeralTo: "alice"},
+		"usage":  {text: "cron add broken", ephemeralTo: "alice"},
+		"public": {text: "cron list"},
+	}
+
+	for name, tt := range tests {
+		posts := h.Send("general", "alice", tt.text)
+		if len(posts) != 1 {
+			t.Fatalf("%s: %d messages are posted, want 1", name, len(posts))
+		}
+		if got := posts[0].EphemeralTo; got != tt.ephemeralTo {
+			t.Errorf("%s: message is visible only to %q, want %q", name, got, tt.ephemeralTo)
+		}
+	}
+}
+
+func Test_Bot_postHelp_middleware(t *testing.T) {
+	// the middleware blocks the messages of bob
+	block := func(next bot.Handler) bot.Handler {
+		return func(ctx context.Context, req *bot.Request) {
+			if req.Hook == plugin.DoActionHook && req.Message.UserID() == "bob" {
+				return
+			}
+			next(ctx, req)
+		}
+	}
+
+	h := bottest.New(t, bot.WithMiddleware(block))
+	h.AddPlugin(cron.New(cron.WithClock(h.Clock())))
+	h.Start()
+
+	if posts := h.Send("general", "bob", "@bot help"); len(posts) != 0 {
+		t.Errorf("%d messages are posted to the blocked user, want 0", len(posts))
+	}
+	if posts := h.Send("general", "alice", "@bot help"); len(posts) != 1 {
+		t.Errorf("%d messages are posted, want 1", len(posts))
+	}
+}
+
+func Test_Bot_registerCommands(t *testing.T) {
+	h := bottest.New(t)
+	h.AddPlugin(cron.New(cron.WithClock(h.Clock())), location.NewPlugin(), refPlugin())
+	h.Start()
+
+	var names []string
+	for _, help := range h.Service().Commands() {
+		names = append(names, help.Name)
+	}
+
+	// refPlugin does not have help
+	want := []string{"cron", "location"}
+	if diff := cmp.Diff(names, want); diff != "" {
+		t.Errorf("registered commands differ: (-got +want)\n%s", diff)
+	}
+}
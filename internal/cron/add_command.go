@@ -4,9 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/kechako/gopher-bot/v2/internal/database"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
 )
 
 type addCommand struct {
@@ -14,23 +15,35 @@ type addCommand struct {
 	bot       Bot
 }
 
-func (cmd *addCommand) Name() string {
-	return "add"
-}
-
-func (cmd *addCommand) HelpCommand() string {
-	return "add <name> <schedule> <command>"
+func (cmd *addCommand) Command() *command.Command {
+	return &command.Command{
+		Name:        "add",
+		Description: "Add a new schedule with specified name.",
+		Flags: []*command.Flag{
+			{Name: "thread", Value: true},
+		},
+		Args: []*command.Arg{
+			{Name: "name"},
+			{Name: "schedule", Arity: 5},
+			{Name: "command", Rest: true},
+		},
+		Handler: cmd.Execute,
+	}
 }
 
-func (cmd *addCommand) Description() string {
-	return "Add a new schedule with specified name."
-}
+func (cmd *addCommand) Execute(ctx context.Context, req *command.Request) (string, error) {
+	// the command runs in the thread that the schedule is added in, unless --thread is specified
+	thread := req.Flag("thread")
+	if r, ok := req.Message.(plugin.ThreadReplier); ok && thread == "" {
+		thread = r.ThreadID()
+	}
 
-func (cmd *addCommand) Execute(ctx context.Context, params []string, channel string) (string, error) {
-	params = params[1:]
-	sch, err := makeSchedule(params, channel)
-	if err != nil {
-		return "", err
+	sch := &database.Schedule{
+		Name:    req.String("name"),
+		Fields:  req.String("schedule"),
+		Command: req.String("command"),
+		Channel: req.Message.ChannelID(),
+		Thread:  thread,
 	}
 
 	db, ok := database.FromContext(ctx)
@@ -38,10 +51,6 @@ func (cmd *addCommand) Execute(ctx context.Context, params []string, channel str
 		return "", errors.New("failed to get database from context")
 	}
 
-	if err := cmd.scheduler.addSchedule(ctx, sch); err != nil {
-		return "", ErrInvalidSyntax
-	}
-
 	if err := db.SaveSchedule(ctx, sch); err != nil {
 		if err == database.ErrDuplicated {
 			return fmt.Sprintf("%s already exists", sch.Name), nil
@@ -50,22 +59,12 @@ func (cmd *addCommand) Execute(ctx context.Context, params []string, channel str
 		return "", fmt.Errorf("failed to add a new schedule %s: %w", sch.Name, err)
 	}
 
-	return fmt.Sprintf("Success to add a new schedule : %s [%s, %s, %s]", sch.Name, sch.Fields, sch.Command, cmd.bot.ChannelName(sch.Channel)), nil
-}
-
-func makeSchedule(params []string, channel string) (*database.Schedule, error) {
-	if len(params) < 7 {
-		return nil, ErrInvalidSyntax
+	if err := cmd.scheduler.addSchedule(ctx, sch); err != nil {
+		if err := db.DeleteScheduleByName(ctx, sch.Name); err != nil {
+			return "", fmt.Errorf("failed to delete the invalid schedule %s: %w", sch.Name, err)
+		}
+		return "", ErrInvalidSyntax
 	}
 
-	name := params[0]
-	fields := strings.Join(params[1:6], " ")
-	command := strings.Join(params[6:], " ")
-
-	return &database.Schedule{
-		Name:    name,
-		Fields:  fields,
-		Command: command,
-		Channel: channel,
-	}, nil
+	return fmt.Sprintf("Success to add a new schedule : %s [%s, %s, %s]", sch.Name, sch.Fields, sch.Command, destination(cmd.bot, sch)), nil
 }
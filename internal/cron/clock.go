@@ -0,0 +1,23 @@
+package cron
+
+import (
+	"context"
+
+	cron "github.com/robfig/cron/v3"
+)
+
+// Clock is the interface that runs jobs on their schedules.
+// It is the subset of *cron.Cron of github.com/robfig/cron/v3, that is used by default.
+// Tests can replace it with a fake clock to control the time.
+type Clock interface {
+	// Schedule adds the job to be run on the schedule, and returns the ID of the entry.
+	Schedule(schedule cron.Schedule, job cron.Job) cron.EntryID
+	// Remove removes the entry of the ID, so that the job is not run any more.
+	Remove(id cron.EntryID)
+	// Start starts running the jobs.
+	Start()
+	// Stop stops running the jobs. The returned context is done when running jobs have completed.
+	Stop() context.Context
+}
+
+var _ Clock = (*cron.Cron)(nil)
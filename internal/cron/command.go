@@ -4,10 +4,10 @@ package cron
 import (
 	"context"
 	"errors"
-	"fmt"
+	"sync"
 
 	"github.com/kechako/gopher-bot/v2/internal/database"
-	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
 	cron "github.com/robfig/cron/v3"
 )
 
@@ -17,41 +17,44 @@ type scheduler interface {
 }
 
 var (
-	ErrInvalidSyntax = errors.New("invalid syntax")
+	ErrInvalidSyntax = command.ErrInvalidSyntax
 )
 
 type Bot interface {
-	ProcessCommand(channelID, command string)
+	ProcessCommand(channelID, threadID, command string)
 	ChannelName(channelID string) string
 }
 
 type Commander interface {
-	Name() string
-	HelpCommand() string
-	Description() string
-	Execute(ctx context.Context, params []string, channel string) (string, error)
+	Command() *command.Command
 }
 
-type CommandFunc func(channelID string, command string)
+type CommandFunc func(channelID, threadID, command string)
 
 type Cron struct {
-	commanders   []Commander
-	commanderMap map[string]Commander
+	commanders []Commander
 
-	cron    *cron.Cron
+	parser  cron.Parser
+	clock   Clock
 	entries map[string]cron.EntryID
+	mux     sync.Mutex
 
 	bot Bot
 }
 
 var _ scheduler = (*Cron)(nil)
 
-func New(bot Bot) *Cron {
+// New returns a new *Cron. If clock is nil, *cron.Cron of the system time is used.
+func New(bot Bot, clock Clock) *Cron {
+	if clock == nil {
+		clock = cron.New()
+	}
+
 	c := &Cron{
-		commanderMap: make(map[string]Commander),
-		cron:         cron.New(cron.WithParser(cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow))),
-		entries:      make(map[string]cron.EntryID),
-		bot:          bot,
+		parser:  cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow),
+		clock:   clock,
+		entries: make(map[string]cron.EntryID),
+		bot:     bot,
 	}
 	c.commanders = []Commander{
 		&addCommand{
@@ -66,18 +69,10 @@ func New(bot Bot) *Cron {
 		},
 		&helpCommand{},
 	}
-	c.init()
 
 	return c
 }
 
-func (c *Cron) init() {
-	for _, cmdr := range c.commanders {
-		name := cmdr.Name()
-		c.commanderMap[name] = cmdr
-	}
-}
-
 func (c *Cron) Start(ctx context.Context) error {
 	db, ok := database.FromContext(ctx)
 	if !ok {
@@ -93,62 +88,66 @@ func (c *Cron) Start(ctx context.Context) error {
 		c.addSchedule(ctx, s)
 	}
 
-	c.cron.Start()
+	c.clock.Start()
 
 	return nil
 }
 
 func (c *Cron) Close() error {
-	c.cron.Stop()
+	c.clock.Stop()
 	return nil
 }
 
-func (c *Cron) Execute(ctx context.Context, params []string, channel string) (string, error) {
-	var cmdName string
-	if len(params) > 0 {
-		cmdName = string(params[0])
-	}
-
-	commander, ok := c.commanderMap[cmdName]
-	if !ok {
-		return "", ErrInvalidSyntax
-	}
-
-	return commander.Execute(ctx, params, channel)
-}
-
-func (c *Cron) HelpCommands(name string) []*plugin.Command {
-	var commands []*plugin.Command
-
+// Commands returns commands to manage cron schedules.
+func (c *Cron) Commands() []*command.Command {
+	commands := make([]*command.Command, 0, len(c.commanders))
 	for _, cmdr := range c.commanders {
-		command := &plugin.Command{
-			Command:     fmt.Sprintf("%s %s", name, cmdr.HelpCommand()),
-			Description: cmdr.Description(),
-		}
-		commands = append(commands, command)
+		commands = append(commands, cmdr.Command())
 	}
 
 	return commands
 }
 
 func (c *Cron) addSchedule(ctx context.Context, s *database.Schedule) error {
-	id, err := c.cron.AddFunc(s.Fields, cron.FuncJob(func() {
-		c.bot.ProcessCommand(s.Channel, s.Command)
-	}))
+	schedule, err := c.parser.Parse(s.Fields)
 	if err != nil {
 		return err
 	}
 
-	c.entries[s.Name] = id
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if id, ok := c.entries[s.Name]; ok {
+		c.clock.Remove(id)
+	}
+
+	c.entries[s.Name] = c.clock.Schedule(schedule, cron.FuncJob(func() {
+		c.bot.ProcessCommand(s.Channel, s.Thread, s.Command)
+	}))
 
 	return nil
 }
 
 func (c *Cron) removeSchedule(ctx context.Context, name string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
 	id, ok := c.entries[name]
 	if !ok {
 		return
 	}
 
-	c.cron.Remove(id)
+	c.clock.Remove(id)
+	delete(c.entries, name)
+}
+
+// destination returns the name of the channel that the schedule runs the command in,
+// with the thread if any.
+func destination(bot Bot, s *database.Schedule) string {
+	name := bot.ChannelName(s.Channel)
+	if s.Thread != "" {
+		name += " (thread " + s.Thread + ")"
+	}
+
+	return name
 }
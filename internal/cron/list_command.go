@@ -7,29 +7,22 @@ import (
 	"strings"
 
 	"github.com/kechako/gopher-bot/v2/internal/database"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
 )
 
 type listCommand struct {
 	bot Bot
 }
 
-func (cmd *listCommand) Name() string {
-	return "list"
-}
-
-func (cmd *listCommand) HelpCommand() string {
-	return "list"
-}
-
-func (cmd *listCommand) Description() string {
-	return "List schedules."
-}
-
-func (cmd *listCommand) Execute(ctx context.Context, params []string, channel string) (string, error) {
-	if len(params) != 1 {
-		return "", ErrInvalidSyntax
+func (cmd *listCommand) Command() *command.Command {
+	return &command.Command{
+		Name:        "list",
+		Description: "List schedules.",
+		Handler:     cmd.Execute,
 	}
+}
 
+func (cmd *listCommand) Execute(ctx context.Context, req *command.Request) (string, error) {
 	db, ok := database.FromContext(ctx)
 	if !ok {
 		return "", errors.New("failed to get database from context")
@@ -49,7 +42,7 @@ func (cmd *listCommand) Execute(ctx context.Context, params []string, channel st
 		if i > 0 {
 			msg.WriteString("\n")
 		}
-		msg.WriteString(fmt.Sprintf("%s : %s %s [%s]", sch.Name, sch.Fields, sch.Command, cmd.bot.ChannelName(sch.Channel)))
+		msg.WriteString(fmt.Sprintf("%s : %s %s [%s]", sch.Name, sch.Fields, sch.Command, destination(cmd.bot, sch)))
 	}
 
 	return msg.String(), nil
@@ -85,11 +85,11 @@ func (db *DB) SaveLocation(ctx context.Context, l *Location) (err error) {
 		err = collectTransaction(tx, err)
 	}()
 
-	_, err = db.FindLocationByName(ctx, l.Name)
-	if err == nil {
+	found, err := db.FindLocationByName(ctx, l.Name)
+	if err == nil && found.ID != l.ID {
 		err = ErrDuplicated
 		return
-	} else if err != ErrNotFound {
+	} else if err != nil && err != ErrNotFound {
 		err = fmt.Errorf("failed to save the location: %w", err)
 		return
 	}
@@ -90,6 +90,12 @@ func Test_Location(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	// saving the location again with the same name is not duplicated
+	updateLoc.Latitude = 51.1234
+	err = db.SaveLocation(ctx, updateLoc)
+	if err != nil {
+		t.Errorf("DB.SaveLocation must not return ErrDuplicated for the same location, got %v", err)
+	}
 
 	loc, err := db.FindLocation(ctx, 1)
 	if err != nil {
@@ -115,6 +121,11 @@ func Test_Location(t *testing.T) {
 	if err != ErrDuplicated {
 		t.Errorf("DB.SaveLocation must be return ErrDuplicated, got %v", err)
 	}
+	updateLoc.Name = "CCCC"
+	err = db.SaveLocation(ctx, updateLoc)
+	if err != ErrDuplicated {
+		t.Errorf("DB.SaveLocation must be return ErrDuplicated for the name of another location, got %v", err)
+	}
 
 	err = db.DeleteLocation(ctx, -1 /* the key does not exist */)
 	if err != ErrNotFound {
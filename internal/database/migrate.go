@@ -5,7 +5,7 @@ import (
 	"fmt"
 )
 
-const CurrentVersion = 1
+const CurrentVersion = 3
 
 func migrate(db *sql.DB) (err error) {
 	tx, err := db.Begin()
@@ -27,12 +27,13 @@ func migrate(db *sql.DB) (err error) {
 		if err != nil {
 			return
 		}
-	} else {
-		for i := version; i < CurrentVersion; i++ {
-			err = updateTable(tx, i, i+1)
-			if err != nil {
-				return
-			}
+		version = 1
+	}
+
+	for i := version; i < CurrentVersion; i++ {
+		err = updateTable(tx, i, i+1)
+		if err != nil {
+			return
 		}
 	}
 
@@ -46,6 +47,8 @@ func migrate(db *sql.DB) (err error) {
 	return
 }
 
+// createTable creates tables of the version 1.
+// Tables of later versions are created by updateTable.
 func createTable(tx *sql.Tx) error {
 	// locations
 	locStmt := `
@@ -80,6 +83,33 @@ func createTable(tx *sql.Tx) error {
 }
 
 func updateTable(tx *sql.Tx, oldVersion, newVersion int) error {
+	switch newVersion {
+	case 2:
+		// outbox_messages
+		stmt := `
+		create table outbox_messages (
+			id      integer primary key,
+			service text,
+			channel text,
+			thread  text,
+			text    text
+		);
+		`
+		_, err := tx.Exec(stmt)
+		if err != nil {
+			return fmt.Errorf("failed to create table [outbox_messages]: %w", err)
+		}
+	case 3:
+		// thread of schedules
+		stmt := `
+		alter table schedules add column thread text not null default '';
+		`
+		_, err := tx.Exec(stmt)
+		if err != nil {
+			return fmt.Errorf("failed to alter table [schedules]: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -61,3 +61,61 @@ func Test_getVersion(t *testing.T) {
 
 	tx.Commit()
 }
+
+func Test_migrate_update(t *testing.T) {
+	path, cleanup, err := makeTestDir("test.db")
+	if err != nil {
+		t.Fatal("failed to create test directory: ", err)
+	}
+
+	t.Cleanup(cleanup)
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	// database of the version 1
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal("failed to begin transaction: ", err)
+	}
+	if err := createTable(tx); err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+	if err := setVersion(tx, 1); err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+	tx.Commit()
+
+	if err := migrate(db); err != nil {
+		t.Fatal("failed to migrate: ", err)
+	}
+
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatal("failed to begin transaction: ", err)
+	}
+	defer tx.Rollback()
+
+	version, err := getVersion(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != CurrentVersion {
+		t.Errorf("got %d, want %d", version, CurrentVersion)
+	}
+
+	if _, err := tx.Exec("select id, service, channel, thread, text from outbox_messages;"); err != nil {
+		t.Errorf("table [outbox_messages] is not created: %v", err)
+	}
+
+	if _, err := tx.Exec("select id, name, channel, thread, fields, command from schedules;"); err != nil {
+		t.Errorf("column [thread] is not added to table [schedules]: %v", err)
+	}
+}
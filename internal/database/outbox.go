@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// OutboxMessage is an outgoing message that is not delivered yet.
+type OutboxMessage struct {
+	ID      int64
+	Service string
+	Channel string
+	Thread  string
+	Text    string
+}
+
+func (m *OutboxMessage) scan(scnr scanner) error {
+	err := scnr.Scan(&m.ID, &m.Service, &m.Channel, &m.Thread, &m.Text)
+	if err != nil {
+		return fmt.Errorf("failed to scan outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// SearchOutboxMessages returns outbox messages of the service in the order they are saved.
+func (db *DB) SearchOutboxMessages(ctx context.Context, service string) ([]*OutboxMessage, error) {
+	rows, err := db.db.QueryContext(ctx, "select id, service, channel, thread, text from outbox_messages where service = ? order by id;", service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search the outbox messages: %w", err)
+	}
+
+	var msgs []*OutboxMessage
+	for rows.Next() {
+		var m OutboxMessage
+		if err := m.scan(rows); err != nil {
+			return nil, fmt.Errorf("failed to search the outbox messages: %w", err)
+		}
+
+		msgs = append(msgs, &m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search the outbox messages: %w", err)
+	}
+
+	return msgs, nil
+}
+
+// SaveOutboxMessage inserts a new outbox message, and sets m.ID.
+func (db *DB) SaveOutboxMessage(ctx context.Context, m *OutboxMessage) error {
+	const stmt = `
+	insert into outbox_messages (service, channel, thread, text) values (?, ?, ?, ?);
+	`
+	res, err := db.db.ExecContext(ctx, stmt, m.Service, m.Channel, m.Thread, m.Text)
+	if err != nil {
+		return fmt.Errorf("failed to insert the outbox message: %w", err)
+	}
+
+	m.ID, _ = res.LastInsertId()
+
+	return nil
+}
+
+// DeleteOutboxMessage deletes the outbox message.
+func (db *DB) DeleteOutboxMessage(ctx context.Context, id int64) error {
+	const stmt = `
+	delete from outbox_messages where id = ?;
+	`
+	res, err := db.db.ExecContext(ctx, stmt, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete the outbox message: %w", err)
+	}
+
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
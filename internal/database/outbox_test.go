@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_OutboxMessage(t *testing.T) {
+	path, cleanup, err := makeTestDir("test.db")
+	if err != nil {
+		t.Fatal("failed to create test directory: ", err)
+	}
+
+	t.Cleanup(cleanup)
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	ctx := context.Background()
+
+	msgs := []*OutboxMessage{
+		{Service: "slack", Channel: "C1", Text: "aaaa"},
+		{Service: "discord", Channel: "C2", Text: "bbbb"},
+		{Service: "slack", Channel: "C3", Thread: "1234.5678", Text: "cccc"},
+	}
+	for _, m := range msgs {
+		if err := db.SaveOutboxMessage(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := db.SearchOutboxMessages(ctx, "slack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, []*OutboxMessage{msgs[0], msgs[2]}); diff != "" {
+		t.Errorf("failed to get outbox messages from database: (-got +want)\n%s", diff)
+	}
+
+	if err := db.DeleteOutboxMessage(ctx, msgs[0].ID); err != nil {
+		t.Error(err)
+	}
+	if err := db.DeleteOutboxMessage(ctx, msgs[0].ID); err != ErrNotFound {
+		t.Errorf("DB.DeleteOutboxMessage must be return ErrNotFound, got %v", err)
+	}
+
+	got, err = db.SearchOutboxMessages(ctx, "slack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, []*OutboxMessage{msgs[2]}); diff != "" {
+		t.Errorf("failed to get outbox messages from database: (-got +want)\n%s", diff)
+	}
+}
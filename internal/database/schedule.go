@@ -11,12 +11,13 @@ type Schedule struct {
 	ID      int64
 	Name    string
 	Channel string
+	Thread  string
 	Fields  string
 	Command string
 }
 
 func (s *Schedule) scan(scnr scanner) error {
-	err := scnr.Scan(&s.ID, &s.Name, &s.Channel, &s.Fields, &s.Command)
+	err := scnr.Scan(&s.ID, &s.Name, &s.Channel, &s.Thread, &s.Fields, &s.Command)
 	if err != nil {
 		return fmt.Errorf("failed to scan schedule: %w", err)
 	}
@@ -25,7 +26,7 @@ func (s *Schedule) scan(scnr scanner) error {
 }
 
 func (db *DB) FindSchedule(ctx context.Context, id int64) (*Schedule, error) {
-	row := db.db.QueryRowContext(ctx, "select id, name, channel, fields, command from schedules where id = ?;", id)
+	row := db.db.QueryRowContext(ctx, "select id, name, channel, thread, fields, command from schedules where id = ?;", id)
 
 	var s Schedule
 	err := s.scan(row)
@@ -40,7 +41,7 @@ func (db *DB) FindSchedule(ctx context.Context, id int64) (*Schedule, error) {
 }
 
 func (db *DB) FindScheduleByName(ctx context.Context, name string) (*Schedule, error) {
-	row := db.db.QueryRowContext(ctx, "select id, name, channel, fields, command from schedules where name = ?;", name)
+	row := db.db.QueryRowContext(ctx, "select id, name, channel, thread, fields, command from schedules where name = ?;", name)
 
 	var s Schedule
 	err := s.scan(row)
@@ -55,7 +56,7 @@ func (db *DB) FindScheduleByName(ctx context.Context, name string) (*Schedule, e
 }
 
 func (db *DB) SearchSchedules(ctx context.Context) ([]*Schedule, error) {
-	rows, err := db.db.QueryContext(ctx, "select id, name, channel, fields, command from schedules;")
+	rows, err := db.db.QueryContext(ctx, "select id, name, channel, thread, fields, command from schedules;")
 	if err != nil {
 		return nil, fmt.Errorf("failed to search the schedules: %w", err)
 	}
@@ -86,11 +87,11 @@ func (db *DB) SaveSchedule(ctx context.Context, s *Schedule) (err error) {
 		err = collectTransaction(tx, err)
 	}()
 
-	_, err = db.FindScheduleByName(ctx, s.Name)
-	if err == nil {
+	found, err := db.FindScheduleByName(ctx, s.Name)
+	if err == nil && found.ID != s.ID {
 		err = ErrDuplicated
 		return
-	} else if err != ErrNotFound {
+	} else if err != nil && err != ErrNotFound {
 		err = fmt.Errorf("failed to save the schedule: %w", err)
 		return
 	}
@@ -106,9 +107,9 @@ func (db *DB) SaveSchedule(ctx context.Context, s *Schedule) (err error) {
 
 func (db *DB) insertSchedule(ctx context.Context, tx *sql.Tx, s *Schedule) error {
 	const stmt = `
-	insert into schedules (name, channel, fields, command) values (?, ?, ?, ?);
+	insert into schedules (name, channel, thread, fields, command) values (?, ?, ?, ?, ?);
 	`
-	res, err := tx.ExecContext(ctx, stmt, s.Name, s.Channel, s.Fields, s.Command)
+	res, err := tx.ExecContext(ctx, stmt, s.Name, s.Channel, s.Thread, s.Fields, s.Command)
 	if err != nil {
 		return fmt.Errorf("failed to insert the schedule: %w", err)
 	}
@@ -120,9 +121,9 @@ func (db *DB) insertSchedule(ctx context.Context, tx *sql.Tx, s *Schedule) error
 
 func (db *DB) updateSchedule(ctx context.Context, tx *sql.Tx, s *Schedule) error {
 	const stmt = `
-	update schedules set name = ?, channel = ?, fields = ?, command = ? where id = ?;
+	update schedules set name = ?, channel = ?, thread = ?, fields = ?, command = ? where id = ?;
 	`
-	res, err := tx.ExecContext(ctx, stmt, s.Name, s.Channel, s.Fields, s.Command, s.ID)
+	res, err := tx.ExecContext(ctx, stmt, s.Name, s.Channel, s.Thread, s.Fields, s.Command, s.ID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrNotFound
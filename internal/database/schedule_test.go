@@ -17,6 +17,7 @@ var testSchedules = []*Schedule{
 	{
 		Name:    "BBBB",
 		Channel: "#test2",
+		Thread:  "1234567890.123456",
 		Fields:  "0 10-16 * * 1-5",
 		Command: "bbbbbb",
 	},
@@ -95,6 +96,12 @@ func Test_Schedule(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	// saving the schedule again with the same name is not duplicated
+	updateSch.Command = "yyyyyy"
+	err = db.SaveSchedule(ctx, updateSch)
+	if err != nil {
+		t.Errorf("DB.SaveSchedule must not return ErrDuplicated for the same schedule, got %v", err)
+	}
 
 	loc, err := db.FindSchedule(ctx, 1)
 	if err != nil {
@@ -121,6 +128,11 @@ func Test_Schedule(t *testing.T) {
 	if err != ErrDuplicated {
 		t.Errorf("DB.SaveSchedule must be return ErrDuplicated, got %v", err)
 	}
+	updateSch.Name = "CCCC"
+	err = db.SaveSchedule(ctx, updateSch)
+	if err != ErrDuplicated {
+		t.Errorf("DB.SaveSchedule must be return ErrDuplicated for the name of another schedule, got %v", err)
+	}
 
 	err = db.DeleteSchedule(ctx, -1 /* the key does not exist */)
 	if err != ErrNotFound {
@@ -0,0 +1,28 @@
+package local
+
+import "github.com/kechako/gopher-bot/v2/plugin"
+
+type channel struct {
+	id   string
+	name string
+}
+
+var _ plugin.Channel = (*channel)(nil)
+
+// NewChannel returns a new plugin.Channel.
+func NewChannel(id, name string) plugin.Channel {
+	return &channel{
+		id:   id,
+		name: name,
+	}
+}
+
+// ID implements the plugin.Channel interface.
+func (ch *channel) ID() string {
+	return ch.id
+}
+
+// Name implements the plugin.Channel interface.
+func (ch *channel) Name() string {
+	return ch.name
+}
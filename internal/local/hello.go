@@ -0,0 +1,142 @@
+package local
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+)
+
+type hello struct {
+	bot *bot
+}
+
+var _ plugin.Hello = (*hello)(nil)
+
+// NewHello returns a new plugin.Hello of the service. l is the logger provided to plugins.
+func NewHello(service Service, l *slog.Logger) plugin.Hello {
+	return &hello{
+		bot: &bot{
+			service: service,
+			l:       l,
+		},
+	}
+}
+
+// Bot implements the plugin.Hello interface.
+func (h *hello) Bot() plugin.Bot {
+	return h.bot
+}
+
+type bot struct {
+	service Service
+	l       *slog.Logger
+}
+
+var (
+	_ plugin.Bot             = (*bot)(nil)
+	_ plugin.ContextPoster   = (*bot)(nil)
+	_ plugin.Editor          = (*bot)(nil)
+	_ plugin.Reactor         = (*bot)(nil)
+	_ plugin.ThreadPoster    = (*bot)(nil)
+	_ plugin.DirectMessenger = (*bot)(nil)
+	_ plugin.RichPoster      = (*bot)(nil)
+	_ plugin.Uploader        = (*bot)(nil)
+)
+
+// Logger implements the plugin.Bot interface.
+func (b *bot) Logger() *slog.Logger {
+	return b.l
+}
+
+// UserID implements the plugin.Bot interface.
+func (b *bot) UserID() string {
+	return b.service.UserID()
+}
+
+// Post implements the plugin.Bot interface.
+func (b *bot) Post(channelID string, text string) {
+	b.service.Post(channelID, text)
+}
+
+// Mention implements the plugin.Bot interface.
+func (b *bot) Mention(channelID, userID, text string) {
+	b.service.Mention(channelID, userID, text)
+}
+
+// PostToThread implements the plugin.ThreadPoster interface.
+func (b *bot) PostToThread(channelID, threadID, text string) {
+	b.service.PostToThread(channelID, threadID, text)
+}
+
+// DirectMessage implements the plugin.DirectMessenger interface.
+func (b *bot) DirectMessage(userID, text string) error {
+	return b.service.DirectMessage(userID, text)
+}
+
+// PostContext implements the plugin.ContextPoster interface.
+func (b *bot) PostContext(ctx context.Context, channelID string, text string) (plugin.MessageRef, error) {
+	return b.service.PostContext(ctx, channelID, text)
+}
+
+// MentionContext implements the plugin.ContextPoster interface.
+func (b *bot) MentionContext(ctx context.Context, channelID, userID, text string) (plugin.MessageRef, error) {
+	return b.service.MentionContext(ctx, channelID, userID, text)
+}
+
+// PostRich implements the plugin.RichPoster interface.
+func (b *bot) PostRich(channelID string, msg *rich.Message) {
+	b.service.PostRich(channelID, msg)
+}
+
+// PostRichContext implements the plugin.RichPoster interface.
+func (b *bot) PostRichContext(ctx context.Context, channelID string, msg *rich.Message) (plugin.MessageRef, error) {
+	return b.service.PostRichContext(ctx, channelID, msg)
+}
+
+// Edit implements the plugin.Editor interface.
+func (b *bot) Edit(ref plugin.MessageRef, text string) error {
+	return b.service.Edit(ref, text)
+}
+
+// Delete implements the plugin.Editor interface.
+func (b *bot) Delete(ref plugin.MessageRef) error {
+	return b.service.Delete(ref)
+}
+
+// React implements the plugin.Reactor interface.
+func (b *bot) React(ref plugin.MessageRef, emoji string) error {
+	return b.service.React(ref, emoji)
+}
+
+// Upload implements the plugin.Uploader interface.
+func (b *bot) Upload(channelID, name string, r io.Reader, comment string) error {
+	return b.service.Upload(channelID, name, r, comment)
+}
+
+// ProcessCommand implements the plugin.Bot interface.
+func (b *bot) ProcessCommand(channelID string, command string) {
+	b.service.ProcessCommand(channelID, command)
+}
+
+// ProcessCommandContext implements the plugin.ContextPoster interface.
+func (b *bot) ProcessCommandContext(ctx context.Context, channelID string, command string) error {
+	return b.service.ProcessCommandContext(ctx, channelID, command)
+}
+
+// ProcessThreadCommand implements the plugin.ThreadPoster interface.
+func (b *bot) ProcessThreadCommand(channelID, threadID, command string) {
+	b.service.ProcessThreadCommand(channelID, threadID, command)
+}
+
+// Channel implements the plugin.Bot interface.
+func (b *bot) Channel(channelID string) plugin.Channel {
+	return b.service.Channel(channelID)
+}
+
+// User implements the plugin.Bot interface.
+func (b *bot) User(userID string) plugin.User {
+	return b.service.User(userID)
+}
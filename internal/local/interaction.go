@@ -0,0 +1,57 @@
+package local
+
+import (
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+)
+
+type interaction struct {
+	service  Service
+	userID   string
+	actionID string
+	value    string
+	target   plugin.MessageRef
+}
+
+var _ plugin.Interaction = (*interaction)(nil)
+
+// NewInteraction returns a new plugin.Interaction of the user with the action in the target message.
+func NewInteraction(service Service, userID, actionID, value string, target plugin.MessageRef) plugin.Interaction {
+	return &interaction{
+		service:  service,
+		userID:   userID,
+		actionID: actionID,
+		value:    value,
+		target:   target,
+	}
+}
+
+// UserID implements the plugin.Interaction interface.
+func (i *interaction) UserID() string {
+	return i.userID
+}
+
+// ActionID implements the plugin.Interaction interface.
+func (i *interaction) ActionID() string {
+	return i.actionID
+}
+
+// Value implements the plugin.Interaction interface.
+func (i *interaction) Value() string {
+	return i.value
+}
+
+// Target implements the plugin.Interaction interface.
+func (i *interaction) Target() plugin.MessageRef {
+	return i.target
+}
+
+// Update implements the plugin.Interaction interface.
+func (i *interaction) Update(msg *rich.Message) error {
+	return i.service.EditRich(i.target, msg)
+}
+
+// PostEphemeral implements the plugin.Interaction interface.
+func (i *interaction) PostEphemeral(text string) {
+	i.service.PostEphemeral(i.target.ChannelID, "", i.userID, text)
+}
@@ -0,0 +1,56 @@
+// Package local implements the plugin types for services that run in the process
+// without a chat platform, i.e. the terminal service and the in-memory service of bottest.
+package local
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+)
+
+// Service is the interface of the service that the plugin types post messages to.
+type Service interface {
+	UserID() string
+	EscapeHelp(help string) string
+	EphemeralHelp() bool
+
+	Post(channelID, text string)
+	PostContext(ctx context.Context, channelID, text string) (plugin.MessageRef, error)
+	PostToThread(channelID, threadID, text string)
+	PostToThreadContext(ctx context.Context, channelID, threadID, text string) (plugin.MessageRef, error)
+	Mention(channelID, userID, text string)
+	MentionContext(ctx context.Context, channelID, userID, text string) (plugin.MessageRef, error)
+	MentionToThread(channelID, threadID, userID, text string)
+	MentionToThreadContext(ctx context.Context, channelID, threadID, userID, text string) (plugin.MessageRef, error)
+	PostEphemeral(channelID, threadID, userID, text string)
+	DirectMessage(userID, text string) error
+	PostRich(channelID string, msg *rich.Message)
+	PostRichContext(ctx context.Context, channelID string, msg *rich.Message) (plugin.MessageRef, error)
+	PostRichToThread(channelID, threadID string, msg *rich.Message)
+
+	Edit(ref plugin.MessageRef, text string) error
+	EditRich(ref plugin.MessageRef, msg *rich.Message) error
+	Delete(ref plugin.MessageRef) error
+	React(ref plugin.MessageRef, emoji string) error
+	Upload(channelID, name string, r io.Reader, comment string) error
+
+	ProcessCommand(channelID, command string)
+	ProcessCommandContext(ctx context.Context, channelID, command string) error
+	ProcessThreadCommand(channelID, threadID, command string)
+
+	Channel(channelID string) plugin.Channel
+	User(userID string) plugin.User
+}
+
+// DirectChannel returns the ID of the direct message channel with the user.
+func DirectChannel(userID string) string {
+	return "@" + userID
+}
+
+// IsDirectChannel returns whether the channel is a direct message channel.
+func IsDirectChannel(channelID string) bool {
+	return strings.HasPrefix(channelID, "@")
+}
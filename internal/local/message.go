@@ -0,0 +1,176 @@
+package local
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+)
+
+type message struct {
+	service     Service
+	channelID   string
+	threadID    string
+	userID      string
+	text        string
+	mentions    []string
+	attachments []plugin.Attachment
+}
+
+var (
+	_ plugin.Message            = (*message)(nil)
+	_ plugin.ContextReplier     = (*message)(nil)
+	_ plugin.Reactor            = (*message)(nil)
+	_ plugin.ThreadReplier      = (*message)(nil)
+	_ plugin.DirectChecker      = (*message)(nil)
+	_ plugin.EphemeralReplier   = (*message)(nil)
+	_ plugin.RichReplier        = (*message)(nil)
+	_ plugin.AttachmentProvider = (*message)(nil)
+)
+
+// NewMessage returns a new plugin.Message posted by the user to the thread of the channel.
+// threadID is empty if the message is not in a thread.
+// Mentions are written as @<user> in the text.
+func NewMessage(service Service, channelID, threadID, userID, text string, attachments ...plugin.Attachment) plugin.Message {
+	m := &message{
+		service:     service,
+		channelID:   channelID,
+		threadID:    threadID,
+		userID:      userID,
+		text:        text,
+		attachments: attachments,
+	}
+	m.init()
+
+	return m
+}
+
+func (m *message) init() {
+	for _, field := range strings.Fields(m.text) {
+		if len(field) > 1 && field[0] == '@' {
+			m.mentions = append(m.mentions, field[1:])
+		}
+	}
+}
+
+// ChannelID implements the plugin.Message interface.
+func (m *message) ChannelID() string {
+	return m.channelID
+}
+
+// UserID implements the plugin.Message interface.
+func (m *message) UserID() string {
+	return m.userID
+}
+
+// IsDirect implements the plugin.DirectChecker interface.
+func (m *message) IsDirect() bool {
+	return IsDirectChannel(m.channelID)
+}
+
+// ThreadID implements the plugin.ThreadReplier interface.
+func (m *message) ThreadID() string {
+	return m.threadID
+}
+
+// Text implements the plugin.Message interface.
+func (m *message) Text() string {
+	return m.text
+}
+
+// Attachments implements the plugin.AttachmentProvider interface.
+func (m *message) Attachments() []plugin.Attachment {
+	if len(m.attachments) == 0 {
+		return nil
+	}
+
+	attachments := make([]plugin.Attachment, len(m.attachments))
+
+	copy(attachments, m.attachments)
+
+	return attachments
+}
+
+// Post implements the plugin.Message interface.
+func (m *message) Post(text string) {
+	m.service.PostToThread(m.ChannelID(), m.ThreadID(), text)
+}
+
+// Mention implements the plugin.Message interface.
+func (m *message) Mention(text string) {
+	m.service.MentionToThread(m.ChannelID(), m.ThreadID(), m.UserID(), text)
+}
+
+// ReplyInThread implements the plugin.ThreadReplier interface.
+// Messages have no IDs to start a thread from,
+// so the message is posted to the channel if it is not in a thread.
+func (m *message) ReplyInThread(text string) {
+	m.Post(text)
+}
+
+// PostToChannel implements the plugin.ThreadReplier interface.
+func (m *message) PostToChannel(text string) {
+	m.service.Post(m.ChannelID(), text)
+}
+
+// PostEphemeral implements the plugin.EphemeralReplier interface.
+func (m *message) PostEphemeral(text string) {
+	m.service.PostEphemeral(m.ChannelID(), m.ThreadID(), m.UserID(), text)
+}
+
+// PostRich implements the plugin.RichReplier interface.
+func (m *message) PostRich(msg *rich.Message) {
+	m.service.PostRichToThread(m.ChannelID(), m.ThreadID(), msg)
+}
+
+// PostContext implements the plugin.ContextReplier interface.
+func (m *message) PostContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	return m.service.PostToThreadContext(ctx, m.ChannelID(), m.ThreadID(), text)
+}
+
+// MentionContext implements the plugin.ContextReplier interface.
+func (m *message) MentionContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	return m.service.MentionToThreadContext(ctx, m.ChannelID(), m.ThreadID(), m.UserID(), text)
+}
+
+// React implements the plugin.Reactor interface.
+func (m *message) React(ref plugin.MessageRef, emoji string) error {
+	return m.service.React(ref, emoji)
+}
+
+// Mentions implements the plugin.Message interface.
+func (m *message) Mentions() []string {
+	if len(m.mentions) == 0 {
+		return nil
+	}
+
+	mentions := make([]string, len(m.mentions))
+
+	copy(mentions, m.mentions)
+
+	return mentions
+}
+
+// MentionTo implements the plugin.Message interface.
+func (m *message) MentionTo(userID string) bool {
+	for _, mentionTo := range m.mentions {
+		if userID == mentionTo {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PostHelp implements the plugin.Message interface.
+// Help messages are posted ephemerally if the service is set to do so.
+func (m *message) PostHelp(help *plugin.Help) {
+	msg := m.service.EscapeHelp(help.String())
+
+	if m.service.EphemeralHelp() {
+		m.PostEphemeral(msg)
+		return
+	}
+	m.Post(msg)
+}
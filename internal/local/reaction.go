@@ -0,0 +1,42 @@
+package local
+
+import "github.com/kechako/gopher-bot/v2/plugin"
+
+type reaction struct {
+	userID string
+	emoji  string
+	target plugin.MessageRef
+	added  bool
+}
+
+var _ plugin.Reaction = (*reaction)(nil)
+
+// NewReaction returns a new plugin.Reaction of the user to the target message.
+func NewReaction(userID, emoji string, target plugin.MessageRef, added bool) plugin.Reaction {
+	return &reaction{
+		userID: userID,
+		emoji:  emoji,
+		target: target,
+		added:  added,
+	}
+}
+
+// UserID implements the plugin.Reaction interface.
+func (r *reaction) UserID() string {
+	return r.userID
+}
+
+// Emoji implements the plugin.Reaction interface.
+func (r *reaction) Emoji() string {
+	return r.emoji
+}
+
+// Target implements the plugin.Reaction interface.
+func (r *reaction) Target() plugin.MessageRef {
+	return r.target
+}
+
+// Added implements the plugin.Reaction interface.
+func (r *reaction) Added() bool {
+	return r.added
+}
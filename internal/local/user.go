@@ -0,0 +1,52 @@
+package local
+
+import "github.com/kechako/gopher-bot/v2/plugin"
+
+type user struct {
+	id  string
+	bot bool
+}
+
+var (
+	_ plugin.User    = (*user)(nil)
+	_ plugin.Profile = (*user)(nil)
+)
+
+// NewUser returns a new plugin.User. All the names of the user are the ID.
+func NewUser(id string, bot bool) plugin.User {
+	return &user{
+		id:  id,
+		bot: bot,
+	}
+}
+
+// ID implements the plugin.User interface.
+func (u *user) ID() string {
+	return u.id
+}
+
+// Name implements the plugin.User interface.
+func (u *user) Name() string {
+	return u.id
+}
+
+// FullName implements the plugin.User interface.
+func (u *user) FullName() string {
+	return u.id
+}
+
+// DisplayName implements the plugin.User interface.
+func (u *user) DisplayName() string {
+	return u.id
+}
+
+// AvatarURL implements the plugin.Profile interface.
+// Users do not have avatars.
+func (u *user) AvatarURL() string {
+	return ""
+}
+
+// IsBot implements the plugin.Profile interface.
+func (u *user) IsBot() bool {
+	return u.bot
+}
@@ -4,31 +4,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
 
 	"github.com/kechako/gopher-bot/v2/internal/database"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
 )
 
 type addCommand struct{}
 
-func (cmd *addCommand) Name() string {
-	return "add"
-}
-
-func (cmd *addCommand) HelpCommand() string {
-	return "add <name> <latitude> <longitude>"
-}
-
-func (cmd *addCommand) Description() string {
-	return "Add a new location with specified name."
+func (cmd *addCommand) Command() *command.Command {
+	return &command.Command{
+		Name:        "add",
+		Description: "Add a new location with specified name.",
+		Args: []*command.Arg{
+			{Name: "name"},
+			{Name: "latitude", Type: command.FloatArg},
+			{Name: "longitude", Type: command.FloatArg},
+		},
+		Handler: cmd.Execute,
+	}
 }
 
-func (cmd *addCommand) Execute(ctx context.Context, params []string) (string, error) {
-	params = params[1:]
-	loc, err := makeLocation(params)
-	if err != nil {
-		return "", err
-	}
+func (cmd *addCommand) Execute(ctx context.Context, req *command.Request) (string, error) {
+	loc := makeLocation(req)
 
 	db, ok := database.FromContext(ctx)
 	if !ok {
@@ -46,26 +43,10 @@ func (cmd *addCommand) Execute(ctx context.Context, params []string) (string, er
 	return fmt.Sprintf("Success to add a new location : %s [%f, %f]", loc.Name, loc.Latitude, loc.Longitude), nil
 }
 
-func makeLocation(params []string) (*database.Location, error) {
-	if len(params) != 3 {
-		return nil, ErrInvalidSyntax
-	}
-
-	name := params[0]
-
-	lat, err := strconv.ParseFloat(params[1], 32)
-	if err != nil {
-		return nil, ErrInvalidSyntax
-	}
-
-	lon, err := strconv.ParseFloat(params[2], 32)
-	if err != nil {
-		return nil, ErrInvalidSyntax
-	}
-
+func makeLocation(req *command.Request) *database.Location {
 	return &database.Location{
-		Name:      name,
-		Latitude:  float32(lat),
-		Longitude: float32(lon),
-	}, nil
+		Name:      req.String("name"),
+		Latitude:  float32(req.Float("latitude")),
+		Longitude: float32(req.Float("longitude")),
+	}
 }
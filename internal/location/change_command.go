@@ -6,28 +6,26 @@ import (
 	"fmt"
 
 	"github.com/kechako/gopher-bot/v2/internal/database"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
 )
 
 type changeCommand struct{}
 
-func (cmd *changeCommand) Name() string {
-	return "change"
-}
-
-func (cmd *changeCommand) HelpCommand() string {
-	return "change <name> <latitude> <longitude>"
-}
-
-func (cmd *changeCommand) Description() string {
-	return "Change a location of the specified name."
+func (cmd *changeCommand) Command() *command.Command {
+	return &command.Command{
+		Name:        "change",
+		Description: "Change a location of the specified name.",
+		Args: []*command.Arg{
+			{Name: "name"},
+			{Name: "latitude", Type: command.FloatArg},
+			{Name: "longitude", Type: command.FloatArg},
+		},
+		Handler: cmd.Execute,
+	}
 }
 
-func (cmd *changeCommand) Execute(ctx context.Context, params []string) (string, error) {
-	params = params[1:]
-	loc, err := makeLocation(params)
-	if err != nil {
-		return "", err
-	}
+func (cmd *changeCommand) Execute(ctx context.Context, req *command.Request) (string, error) {
+	loc := makeLocation(req)
 
 	db, ok := database.FromContext(ctx)
 	if !ok {
@@ -2,74 +2,30 @@
 package location
 
 import (
-	"context"
-	"errors"
-	"fmt"
-
-	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
 )
 
 var (
-	ErrInvalidSyntax = errors.New("invalid syntax")
+	ErrInvalidSyntax = command.ErrInvalidSyntax
 )
 
 type Commander interface {
-	Name() string
-	HelpCommand() string
-	Description() string
-	Execute(ctx context.Context, params []string) (string, error)
-}
-
-type Command struct {
-	commanders   []Commander
-	commanderMap map[string]Commander
-}
-
-func New() *Command {
-	cmd := &Command{
-		commanders: []Commander{
-			&addCommand{},
-			&listCommand{},
-			&removeCommand{},
-			&changeCommand{},
-			&helpCommand{},
-		},
-		commanderMap: make(map[string]Commander),
-	}
-	cmd.init()
-	return cmd
-}
-
-func (cmd *Command) init() {
-	for _, cmdr := range cmd.commanders {
-		name := cmdr.Name()
-		cmd.commanderMap[name] = cmdr
-	}
+	Command() *command.Command
 }
 
-func (cmd *Command) Execute(ctx context.Context, params []string) (string, error) {
-	var cmdName string
-	if len(params) > 0 {
-		cmdName = string(params[0])
-	}
-
-	commander, ok := cmd.commanderMap[cmdName]
-	if !ok {
-		return "", ErrInvalidSyntax
-	}
-
-	return commander.Execute(ctx, params)
+var commanders = []Commander{
+	&addCommand{},
+	&listCommand{},
+	&removeCommand{},
+	&changeCommand{},
+	&helpCommand{},
 }
 
-func (cmd *Command) HelpCommands(name string) []*plugin.Command {
-	var commands []*plugin.Command
-
-	for _, cmdr := range cmd.commanders {
-		command := &plugin.Command{
-			Command:     fmt.Sprintf("%s %s", name, cmdr.HelpCommand()),
-			Description: cmdr.Description(),
-		}
-		commands = append(commands, command)
+// Commands returns commands to manage locations.
+func Commands() []*command.Command {
+	commands := make([]*command.Command, 0, len(commanders))
+	for _, cmdr := range commanders {
+		commands = append(commands, cmdr.Command())
 	}
 
 	return commands
@@ -2,22 +2,20 @@ package location
 
 import (
 	"context"
+
+	"github.com/kechako/gopher-bot/v2/plugin/command"
 )
 
 type helpCommand struct{}
 
-func (cmd *helpCommand) Name() string {
-	return "help"
-}
-
-func (cmd *helpCommand) HelpCommand() string {
-	return "help"
-}
-
-func (cmd *helpCommand) Description() string {
-	return "Show this help message."
+func (cmd *helpCommand) Command() *command.Command {
+	return &command.Command{
+		Name:        "help",
+		Description: "Show this help message.",
+		Handler:     cmd.Execute,
+	}
 }
 
-func (cmd *helpCommand) Execute(ctx context.Context, params []string) (string, error) {
+func (cmd *helpCommand) Execute(ctx context.Context, req *command.Request) (string, error) {
 	return "", ErrInvalidSyntax
 }
@@ -7,27 +7,20 @@ import (
 	"strings"
 
 	"github.com/kechako/gopher-bot/v2/internal/database"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
 )
 
 type listCommand struct{}
 
-func (cmd *listCommand) Name() string {
-	return "list"
-}
-
-func (cmd *listCommand) HelpCommand() string {
-	return "list"
-}
-
-func (cmd *listCommand) Description() string {
-	return "List locations."
-}
-
-func (cmd *listCommand) Execute(ctx context.Context, params []string) (string, error) {
-	if len(params) != 1 {
-		return "", ErrInvalidSyntax
+func (cmd *listCommand) Command() *command.Command {
+	return &command.Command{
+		Name:        "list",
+		Description: "List locations.",
+		Handler:     cmd.Execute,
 	}
+}
 
+func (cmd *listCommand) Execute(ctx context.Context, req *command.Request) (string, error) {
 	db, ok := database.FromContext(ctx)
 	if !ok {
 		return "", errors.New("failed to get database from context")
@@ -6,34 +6,29 @@ import (
 	"fmt"
 
 	"github.com/kechako/gopher-bot/v2/internal/database"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
 )
 
 type removeCommand struct{}
 
-func (cmd *removeCommand) Name() string {
-	return "remove"
-}
-
-func (cmd *removeCommand) HelpCommand() string {
-	return "remove <name>"
-}
-
-func (cmd *removeCommand) Description() string {
-	return "Remove a location of the specified name"
-}
-
-func (cmd *removeCommand) Execute(ctx context.Context, params []string) (string, error) {
-	params = params[1:]
-	if len(params) != 1 {
-		return "", ErrInvalidSyntax
+func (cmd *removeCommand) Command() *command.Command {
+	return &command.Command{
+		Name:        "remove",
+		Description: "Remove a location of the specified name",
+		Args: []*command.Arg{
+			{Name: "name"},
+		},
+		Handler: cmd.Execute,
 	}
+}
 
+func (cmd *removeCommand) Execute(ctx context.Context, req *command.Request) (string, error) {
 	db, ok := database.FromContext(ctx)
 	if !ok {
 		return "", errors.New("failed to get database from context")
 	}
 
-	name := params[0]
+	name := req.String("name")
 
 	err := db.DeleteLocationByName(ctx, name)
 	if err != nil {
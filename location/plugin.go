@@ -3,18 +3,19 @@ package location
 
 import (
 	"context"
+	"errors"
 	"log/slog"
-	"strings"
 
 	"github.com/kechako/gopher-bot/v2/internal/location"
 	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
 )
 
 const commandName = "loc"
 
 type locationPlugin struct {
-	cmd *location.Command
-	l   *slog.Logger
+	router *command.Router
+	l      *slog.Logger
 }
 
 var _ plugin.Plugin = (*locationPlugin)(nil)
@@ -22,7 +23,7 @@ var _ plugin.Plugin = (*locationPlugin)(nil)
 // NewPlugin returns a new plugin.Plugin that manages locations.
 func NewPlugin() plugin.Plugin {
 	return &locationPlugin{
-		cmd: location.New(),
+		router: command.New(commandName, location.Commands()...),
 	}
 }
 
@@ -31,19 +32,17 @@ func (p *locationPlugin) Hello(ctx context.Context, hello plugin.Hello) {
 }
 
 func (p *locationPlugin) DoAction(ctx context.Context, msg plugin.Message) {
-	params := strings.Fields(msg.Text())
-	if len(params) == 0 || params[0] != commandName {
-		return
-	}
-
-	retMsg, err := p.cmd.Execute(ctx, params[1:])
+	retMsg, err := p.router.Execute(ctx, msg)
 	if err != nil {
-		if err == location.ErrInvalidSyntax {
+		switch {
+		case errors.Is(err, command.ErrNotMatched):
+		case errors.Is(err, command.ErrInvalidSyntax):
 			msg.PostHelp(p.Help(ctx))
-			return
+		case errors.Is(err, command.ErrDirectOnly):
+			command.PostDirectOnly(msg)
+		default:
+			p.l.Error("failed to do plugin action", slog.Any("err", err))
 		}
-
-		p.l.Error("failed to do plugin action", slog.Any("err", err))
 		return
 	}
 
@@ -54,6 +53,6 @@ func (p *locationPlugin) Help(ctx context.Context) *plugin.Help {
 	return &plugin.Help{
 		Name:        "location",
 		Description: "Management location. Locations are used by each plugin.",
-		Commands:    p.cmd.HelpCommands(commandName),
+		Commands:    p.router.HelpCommands(),
 	}
 }
@@ -0,0 +1,72 @@
+package location_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kechako/gopher-bot/v2/bottest"
+	"github.com/kechako/gopher-bot/v2/location"
+)
+
+var pluginTests = []struct {
+	text  string
+	posts []string
+}{
+	{
+		text:  "loc list",
+		posts: []string{"Location list is empty."},
+	},
+	{
+		text:  `loc add "New York" 40.7 -74.0`,
+		posts: []string{"Success to add a new location : New York [40.700001, -74.000000]"},
+	},
+	{
+		text:  "loc add tokyo 35.7 139.7",
+		posts: []string{"Success to add a new location : tokyo [35.700001, 139.699997]"},
+	},
+	{
+		text:  "loc add tokyo 35.7 139.7",
+		posts: []string{"tokyo already exists"},
+	},
+	{
+		text:  "loc change tokyo 35.6 139.8",
+		posts: []string{"Success to change a location : tokyo [35.599998, 139.800003]"},
+	},
+	{
+		text:  "loc list",
+		posts: []string{"New York [40.700001, -74.000000]\ntokyo [35.599998, 139.800003]"},
+	},
+	{
+		text:  `loc remove "New York"`,
+		posts: []string{"Success to remove a location : New York"},
+	},
+	{
+		text:  "loc remove osaka",
+		posts: []string{"osaka does not exist."},
+	},
+	{
+		text:  "hello",
+		posts: []string{},
+	},
+}
+
+func Test_Plugin(t *testing.T) {
+	h := bottest.New(t)
+	h.AddPlugin(location.NewPlugin())
+	h.Start()
+
+	for _, tt := range pluginTests {
+		posts := h.Send("general", "alice", tt.text)
+		if diff := cmp.Diff(bottest.Texts(posts), tt.posts); diff != "" {
+			t.Errorf("failed to handle %q: (-got +want)\n%s", tt.text, diff)
+		}
+	}
+}
+
+func Test_Plugin_transcript(t *testing.T) {
+	h := bottest.New(t)
+	h.AddPlugin(location.NewPlugin())
+	h.Start()
+
+	h.RunTranscript("testdata/location.txt")
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+// Request represents a call of a plugin hook.
+type Request struct {
+	// Hook is the hook to be called.
+	Hook plugin.Hook
+	// Plugin is the plugin to be called.
+	Plugin plugin.Plugin
+	// Hello is the bot information. It is set for plugin.HelloHook.
+	Hello plugin.Hello
+	// Message is the received message. It is set for plugin.DoActionHook and plugin.MessageEditedHook.
+	// Middlewares can replace the message before it reaches the plugin.
+	Message plugin.Message
+	// Reaction is the received reaction. It is set for plugin.ReactionHook.
+	Reaction plugin.Reaction
+	// Interaction is the received interaction. It is set for plugin.InteractionHook.
+	Interaction plugin.Interaction
+}
+
+// Handler handles a plugin hook call.
+type Handler func(ctx context.Context, req *Request)
+
+// Middleware wraps a Handler to intercept plugin hook calls.
+// A middleware can stop the call by returning without calling next.
+type Middleware func(next Handler) Handler
+
+// chain wraps h with the middlewares.
+// The first middleware is the outermost.
+func chain(h Handler, mws []Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+
+	return h
+}
+
+// Recoverer returns a Middleware that recovers panics of plugins and logs them.
+func Recoverer(l *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) {
+			defer func() {
+				if err := recover(); err != nil {
+					l.Error(fmt.Sprintf("recover plugin.%s()", req.Hook), slog.Any("err", err), slog.String("plugin", pluginName(req.Plugin)))
+				}
+			}()
+
+			next(ctx, req)
+		}
+	}
+}
+
+// RequestLogger returns a Middleware that logs plugin hook calls.
+func RequestLogger(l *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) {
+			attrs := []any{
+				slog.String("hook", req.Hook.String()),
+				slog.String("plugin", pluginName(req.Plugin)),
+			}
+			if req.Message != nil {
+				attrs = append(attrs,
+					slog.String("channel_id", req.Message.ChannelID()),
+					slog.String("user_id", req.Message.UserID()))
+			}
+			if req.Reaction != nil {
+				attrs = append(attrs,
+					slog.String("channel_id", req.Reaction.Target().ChannelID),
+					slog.String("user_id", req.Reaction.UserID()))
+			}
+			if req.Interaction != nil {
+				attrs = append(attrs,
+					slog.String("channel_id", req.Interaction.Target().ChannelID),
+					slog.String("user_id", req.Interaction.UserID()),
+					slog.String("action_id", req.Interaction.ActionID()))
+			}
+
+			start := time.Now()
+			next(ctx, req)
+
+			l.Info("plugin call", append(attrs, slog.Duration("elapsed", time.Since(start)))...)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+func Test_chain(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req *Request) {
+				calls = append(calls, name+" before")
+				next(ctx, req)
+				calls = append(calls, name+" after")
+			}
+		}
+	}
+
+	h := chain(func(ctx context.Context, req *Request) {
+		calls = append(calls, "handler")
+	}, []Middleware{mw("first"), mw("second")})
+	h(context.Background(), &Request{Hook: plugin.DoActionHook})
+
+	want := []string{
+		"first before",
+		"second before",
+		"handler",
+		"second after",
+		"first after",
+	}
+	if diff := cmp.Diff(calls, want); diff != "" {
+		t.Errorf("middlewares are not called in order: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_Recoverer(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, nil))
+
+	h := chain(func(ctx context.Context, req *Request) {
+		panic("test panic")
+	}, []Middleware{Recoverer(l)})
+	h(context.Background(), &Request{Hook: plugin.HelpHook})
+
+	if log := buf.String(); !strings.Contains(log, "recover plugin.Help()") {
+		t.Errorf("Recoverer must log the recovered panic, got %q", log)
+	}
+}
@@ -0,0 +1,477 @@
+// Package command provides a declarative command router for plugins.
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/util"
+)
+
+var (
+	// ErrInvalidSyntax is the error used for the invalid command syntax.
+	ErrInvalidSyntax = errors.New("invalid syntax")
+	// ErrNotMatched is the error used for the message that is not a command of the router.
+	ErrNotMatched = errors.New("not matched")
+	// ErrDirectOnly is the error used for the command that is only available in direct messages,
+	// requested in other channels.
+	ErrDirectOnly = errors.New("command is only available in direct messages")
+)
+
+// SyntaxError records an invalid syntax of a command and its usage.
+type SyntaxError struct {
+	// Usage is the usage of the command.
+	Usage string
+	// Err is the reason of the error.
+	Err error
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%v: %v, usage: %s", ErrInvalidSyntax, e.Err, e.Usage)
+	}
+	return fmt.Sprintf("%v, usage: %s", ErrInvalidSyntax, e.Usage)
+}
+
+// Is reports whether the target is ErrInvalidSyntax.
+func (e *SyntaxError) Is(target error) bool {
+	return target == ErrInvalidSyntax
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// ArgType represents a type of command argument.
+type ArgType int
+
+const (
+	StringArg ArgType = iota
+	IntArg
+	FloatArg
+)
+
+// Arg describes an argument of a command.
+type Arg struct {
+	// Name is a name of the argument.
+	Name string
+	// Type is a type of the argument.
+	Type ArgType
+	// Arity is a number of words that the argument takes.
+	// The words are joined with a space. Zero means one word.
+	Arity int
+	// Rest indicates the argument takes the rest of the line as it is,
+	// including quotes and white spaces. It must be the last argument.
+	Rest bool
+	// Optional indicates the argument can be omitted.
+	Optional bool
+}
+
+func (a *Arg) usage() string {
+	if a.Optional {
+		return "[<" + a.Name + ">]"
+	}
+	return "<" + a.Name + ">"
+}
+
+// Flag describes an option of a command.
+type Flag struct {
+	// Name is a name of the flag, specified as --name.
+	Name string
+	// Short is a short name of the flag, specified as -s.
+	Short string
+	// Value indicates the flag takes a value.
+	// Flags that do not take a value are set to "true".
+	Value bool
+}
+
+func (f *Flag) usage() string {
+	if f.Value {
+		return "[--" + f.Name + "=<" + f.Name + ">]"
+	}
+	return "[--" + f.Name + "]"
+}
+
+// HandlerFunc is the function to execute a command.
+// The returned string is posted to the channel.
+type HandlerFunc func(ctx context.Context, req *Request) (string, error)
+
+// Command describes a command of a plugin.
+type Command struct {
+	// Name is a name of the command.
+	Name string
+	// Description is a description of the command.
+	Description string
+	// Flags are options of the command.
+	Flags []*Flag
+	// Args are arguments of the command.
+	Args []*Arg
+	// Subcommands are subcommands of the command.
+	Subcommands []*Command
+	// DirectOnly indicates the command and its subcommands are only available in direct messages.
+	// It is useful for sensitive commands, such as managing secrets.
+	DirectOnly bool
+	// Handler executes the command.
+	// It can be nil if the command only has subcommands.
+	Handler HandlerFunc
+}
+
+func (cmd *Command) subcommand(name string) *Command {
+	for _, sub := range cmd.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+
+	return nil
+}
+
+// Request represents a command request.
+type Request struct {
+	// Message is the message that requests the command.
+	Message plugin.Message
+
+	args  map[string]any
+	flags map[string]string
+}
+
+// Flag returns the value of the flag of the name.
+// Returns an empty string if the flag is not specified.
+func (r *Request) Flag(name string) string {
+	return r.flags[name]
+}
+
+// Has returns whether the argument of the name is specified.
+func (r *Request) Has(name string) bool {
+	_, ok := r.args[name]
+	return ok
+}
+
+// String returns the argument of the name as string.
+func (r *Request) String(name string) string {
+	switch v := r.args[name].(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// Int returns the argument of the name as int.
+func (r *Request) Int(name string) int {
+	v, _ := r.args[name].(int)
+	return v
+}
+
+// Float returns the argument of the name as float64.
+func (r *Request) Float(name string) float64 {
+	switch v := r.args[name].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+// Router dispatches messages to commands.
+type Router struct {
+	name     string
+	commands []*Command
+}
+
+// New returns a new *Router of the command name.
+func New(name string, commands ...*Command) *Router {
+	return &Router{
+		name:     name,
+		commands: commands,
+	}
+}
+
+// Name returns the command name of the router.
+func (r *Router) Name() string {
+	return r.name
+}
+
+// Add adds commands to the router.
+func (r *Router) Add(commands ...*Command) {
+	r.commands = append(r.commands, commands...)
+}
+
+// Execute executes the command requested by the message.
+// It returns ErrNotMatched if the message is not a command of the router,
+// and an error that matches ErrInvalidSyntax if the syntax is invalid.
+func (r *Router) Execute(ctx context.Context, msg plugin.Message) (string, error) {
+	if words := strings.Fields(msg.Text()); len(words) == 0 || words[0] != r.name {
+		return "", ErrNotMatched
+	}
+
+	// an unterminated quote is an error unless it is in a Rest argument, see parseArgs
+	args, _ := util.ParseArgs(msg.Text())
+	if args.Arg(0) != r.name {
+		return "", ErrNotMatched
+	}
+	args = args.Slice(1)
+
+	if args.Len() == 0 {
+		return "", &SyntaxError{Usage: r.name + " <command>", Err: args.Err()}
+	}
+
+	var cmd *Command
+	for _, c := range r.commands {
+		if c.Name == args.Arg(0) {
+			cmd = c
+			break
+		}
+	}
+	if cmd == nil {
+		return "", &SyntaxError{
+			Usage: r.name + " <command>",
+			Err:   fmt.Errorf("unknown command %q", args.Arg(0)),
+		}
+	}
+
+	path := []string{r.name, cmd.Name}
+	directOnly := cmd.DirectOnly
+	args = args.Slice(1)
+	for args.Len() > 0 {
+		sub := cmd.subcommand(args.Arg(0))
+		if sub == nil {
+			break
+		}
+		cmd = sub
+		path = append(path, sub.Name)
+		directOnly = directOnly || sub.DirectOnly
+		args = args.Slice(1)
+	}
+
+	if directOnly && !isDirect(msg) {
+		return "", ErrDirectOnly
+	}
+
+	usage := commandUsage(strings.Join(path, " "), cmd)
+	if cmd.Handler == nil {
+		return "", &SyntaxError{Usage: usage}
+	}
+
+	flags, args, err := parseFlags(cmd.Flags, args)
+	if err != nil {
+		return "", &SyntaxError{Usage: usage, Err: err}
+	}
+
+	values, err := parseArgs(cmd.Args, args)
+	if err != nil {
+		return "", &SyntaxError{Usage: usage, Err: err}
+	}
+
+	return cmd.Handler(ctx, &Request{
+		Message: msg,
+		args:    values,
+		flags:   flags,
+	})
+}
+
+func parseFlags(defs []*Flag, args *util.Args) (map[string]string, *util.Args, error) {
+	if len(defs) == 0 {
+		return nil, args, nil
+	}
+
+	var valueNames []string
+	for _, def := range defs {
+		if def.Value {
+			valueNames = append(valueNames, def.Name)
+			if def.Short != "" {
+				valueNames = append(valueNames, def.Short)
+			}
+		}
+	}
+
+	parsed, args, err := args.Flags(valueNames...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	flags := make(map[string]string)
+	for name, value := range parsed {
+		def := findFlag(defs, name)
+		if def == nil {
+			return nil, nil, fmt.Errorf("unknown flag: %s", name)
+		}
+		flags[def.Name] = value
+	}
+
+	return flags, args, nil
+}
+
+func findFlag(defs []*Flag, name string) *Flag {
+	for _, def := range defs {
+		if def.Name == name || (def.Short != "" && def.Short == name) {
+			return def
+		}
+	}
+
+	return nil
+}
+
+func parseArgs(defs []*Arg, args *util.Args) (map[string]any, error) {
+	values := make(map[string]any)
+
+	for _, def := range defs {
+		if args.Len() == 0 && (!def.Rest || args.Err() == nil) {
+			if def.Optional {
+				continue
+			}
+			if err := args.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("missing argument <%s>", def.Name)
+		}
+
+		var value string
+		if def.Rest {
+			// the rest of the line is taken as it is, even if it has an unterminated quote
+			value = args.Rest(0)
+			args = new(util.Args)
+		} else {
+			n := def.Arity
+			if n <= 0 {
+				n = 1
+			}
+			if args.Len() < n {
+				if def.Optional {
+					continue
+				}
+				return nil, fmt.Errorf("missing argument <%s>", def.Name)
+			}
+
+			value = strings.Join(args.Strings()[:n], " ")
+			args = args.Slice(n)
+		}
+
+		v, err := convertArg(def, value)
+		if err != nil {
+			return nil, err
+		}
+		values[def.Name] = v
+	}
+
+	if args.Len() > 0 {
+		return nil, fmt.Errorf("too many arguments: %s", args.Rest(0))
+	}
+	if err := args.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func convertArg(def *Arg, value string) (any, error) {
+	switch def.Type {
+	case IntArg:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("<%s> must be an integer: %s", def.Name, value)
+		}
+		return v, nil
+	case FloatArg:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("<%s> must be a number: %s", def.Name, value)
+		}
+		return v, nil
+	}
+
+	return value, nil
+}
+
+func commandUsage(path string, cmd *Command) string {
+	var usage strings.Builder
+	usage.WriteString(path)
+	for _, flag := range cmd.Flags {
+		usage.WriteString(" ")
+		usage.WriteString(flag.usage())
+	}
+	for _, arg := range cmd.Args {
+		usage.WriteString(" ")
+		usage.WriteString(arg.usage())
+	}
+
+	return usage.String()
+}
+
+// HelpCommands returns help information of the commands.
+func (r *Router) HelpCommands() []*plugin.Command {
+	var commands []*plugin.Command
+
+	for _, cmd := range r.commands {
+		commands = appendHelpCommands(commands, r.name, cmd, false)
+	}
+
+	return commands
+}
+
+func appendHelpCommands(commands []*plugin.Command, parent string, cmd *Command, directOnly bool) []*plugin.Command {
+	path := parent + " " + cmd.Name
+	directOnly = directOnly || cmd.DirectOnly
+
+	if cmd.Handler != nil {
+		commands = append(commands, &plugin.Command{
+			Command:     commandUsage(path, cmd),
+			Description: cmd.Description,
+			DirectOnly:  directOnly,
+			Args:        helpArgs(cmd.Args),
+		})
+	}
+
+	for _, sub := range cmd.Subcommands {
+		commands = appendHelpCommands(commands, path, sub, directOnly)
+	}
+
+	return commands
+}
+
+// helpArgs returns help information of the arguments.
+func helpArgs(args []*Arg) []*plugin.CommandArg {
+	if len(args) == 0 {
+		return nil
+	}
+
+	helps := make([]*plugin.CommandArg, len(args))
+	for i, arg := range args {
+		helps[i] = &plugin.CommandArg{
+			Name:  arg.Name,
+			Arity: arg.Arity,
+			Rest:  arg.Rest,
+		}
+	}
+
+	return helps
+}
+
+// isDirect returns whether the message was posted in a direct message channel.
+// Messages of the services that do not have direct message channels are not direct.
+func isDirect(msg plugin.Message) bool {
+	d, ok := msg.(plugin.DirectChecker)
+	return ok && d.IsDirect()
+}
+
+// PostDirectOnly tells the user that the command of msg is only available in direct messages.
+// Plugins call it when Router.Execute returns ErrDirectOnly.
+// The reply is ephemeral if the message implements plugin.EphemeralReplier.
+func PostDirectOnly(msg plugin.Message) {
+	const text = "This command is only available in direct messages."
+
+	if r, ok := msg.(plugin.EphemeralReplier); ok {
+		r.PostEphemeral(text)
+		return
+	}
+
+	msg.Post(text)
+}
@@ -0,0 +1,248 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+type testMessage struct {
+	plugin.Message
+	text   string
+	direct bool
+}
+
+func (m *testMessage) Text() string {
+	return m.text
+}
+
+func (m *testMessage) IsDirect() bool {
+	return m.direct
+}
+
+func echoArgs(names ...string) HandlerFunc {
+	return func(ctx context.Context, req *Request) (string, error) {
+		var s string
+		for _, name := range names {
+			if req.Has(name) {
+				s += fmt.Sprintf("[%s=%s]", name, req.String(name))
+			}
+		}
+		return s, nil
+	}
+}
+
+var testRouter = New("test",
+	&Command{
+		Name:        "add",
+		Description: "Add a new item.",
+		Args: []*Arg{
+			{Name: "name"},
+			{Name: "count", Type: IntArg},
+			{Name: "memo", Rest: true, Optional: true},
+		},
+		Handler: echoArgs("name", "count", "memo"),
+	},
+	&Command{
+		Name:        "schedule",
+		Description: "Set a schedule.",
+		Args: []*Arg{
+			{Name: "fields", Arity: 3},
+			{Name: "ratio", Type: FloatArg},
+		},
+		Handler: echoArgs("fields", "ratio"),
+	},
+	&Command{
+		Name:        "post",
+		Description: "Post a message.",
+		Flags: []*Flag{
+			{Name: "channel", Short: "c", Value: true},
+			{Name: "silent"},
+		},
+		Args: []*Arg{
+			{Name: "text"},
+		},
+		Handler: func(ctx context.Context, req *Request) (string, error) {
+			return fmt.Sprintf("[channel=%s][silent=%s][text=%s]", req.Flag("channel"), req.Flag("silent"), req.String("text")), nil
+		},
+	},
+	&Command{
+		Name: "config",
+		Subcommands: []*Command{
+			{
+				Name:        "get",
+				Description: "Get a config.",
+				Args:        []*Arg{{Name: "key"}},
+				Handler:     echoArgs("key"),
+			},
+			{
+				Name:        "set",
+				Description: "Set a config.",
+				Args:        []*Arg{{Name: "key"}, {Name: "value"}},
+				Handler:     echoArgs("key", "value"),
+			},
+		},
+	},
+	&Command{
+		Name:       "secret",
+		DirectOnly: true,
+		Subcommands: []*Command{
+			{
+				Name:        "set",
+				Description: "Set a secret.",
+				Args:        []*Arg{{Name: "key"}, {Name: "value"}},
+				Handler:     echoArgs("key", "value"),
+			},
+		},
+	},
+)
+
+var routerTests = map[string]struct {
+	text   string
+	direct bool
+	ret    string
+	err    error
+}{
+	"not matched":             {text: "other add a 1", err: ErrNotMatched},
+	"empty":                   {text: "", err: ErrNotMatched},
+	"no command":              {text: "test", err: ErrInvalidSyntax},
+	"unknown command":         {text: "test remove a", err: ErrInvalidSyntax},
+	"add":                     {text: "test add a 1", ret: "[name=a][count=1]"},
+	"add with memo":           {text: "test add a 1 hello  world", ret: "[name=a][count=1][memo=hello  world]"},
+	"add quoted":              {text: `test add "a b" 1 'hello' "world"`, ret: `[name=a b][count=1][memo='hello' "world"]`},
+	"unterminated quote":      {text: `test add "a b 1`, err: ErrInvalidSyntax},
+	"apostrophe in rest":      {text: `test add a 1 don't stop`, ret: `[name=a][count=1][memo=don't stop]`},
+	"apostrophe in rest only": {text: `test add a 1 'quoted`, ret: `[name=a][count=1][memo='quoted]`},
+	"apostrophe after args":   {text: `test config get don't`, err: ErrInvalidSyntax},
+	"unterminated subcommand": {text: `test config "get k`, err: ErrInvalidSyntax},
+	"flags":                   {text: `test post --channel=C1 --silent "hello world"`, ret: "[channel=C1][silent=true][text=hello world]"},
+	"short flag":              {text: `test post -c C2 hello`, ret: "[channel=C2][silent=][text=hello]"},
+	"negative number":         {text: `test post -1`, ret: "[channel=][silent=][text=-1]"},
+	"unknown flag":            {text: `test post --unknown hello`, err: ErrInvalidSyntax},
+	"add invalid int":         {text: "test add a b", err: ErrInvalidSyntax},
+	"add missing":             {text: "test add a", err: ErrInvalidSyntax},
+	"arity":                   {text: "test schedule 1 2 3 0.5", ret: "[fields=1 2 3][ratio=0.5]"},
+	"arity too few":           {text: "test schedule 1 2", err: ErrInvalidSyntax},
+	"too many":                {text: "test schedule 1 2 3 0.5 x", err: ErrInvalidSyntax},
+	"subcommand":              {text: "test config set k v", ret: "[key=k][value=v]"},
+	"no subcommand":           {text: "test config", err: ErrInvalidSyntax},
+	"unknown subcommand":      {text: "test config del k", err: ErrInvalidSyntax},
+	"direct only":             {text: "test secret set k v", direct: true, ret: "[key=k][value=v]"},
+	"direct only public":      {text: "test secret set k v", err: ErrDirectOnly},
+}
+
+func Test_Router_Execute(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for name, tt := range routerTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			ret, err := testRouter.Execute(ctx, &testMessage{text: tt.text, direct: tt.direct})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Router.Execute(%q) => error %v, want %v", tt.text, err, tt.err)
+			}
+			if ret != tt.ret {
+				t.Errorf("Router.Execute(%q) => %q, want %q", tt.text, ret, tt.ret)
+			}
+		})
+	}
+}
+
+// textMessage is a plugin.Message of the services that do not have direct message channels.
+type textMessage struct {
+	plugin.Message
+	text string
+}
+
+func (m *textMessage) Text() string {
+	return m.text
+}
+
+func Test_Router_Execute_notDirectChecker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, err := testRouter.Execute(ctx, &textMessage{text: "test secret set k v"})
+	if !errors.Is(err, ErrDirectOnly) {
+		t.Errorf("Router.Execute() => error %v, want %v", err, ErrDirectOnly)
+	}
+
+	ret, err := testRouter.Execute(ctx, &textMessage{text: "test add apple 3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[name=apple][count=3]"; ret != want {
+		t.Errorf("Router.Execute() => %q, want %q", ret, want)
+	}
+}
+
+// postMessage is a plugin.Message that records the posted messages.
+type postMessage struct {
+	plugin.Message
+	posts []string
+}
+
+func (m *postMessage) Post(text string) {
+	m.posts = append(m.posts, text)
+}
+
+func Test_PostDirectOnly_notEphemeralReplier(t *testing.T) {
+	t.Parallel()
+
+	msg := &postMessage{}
+	PostDirectOnly(msg)
+
+	want := []string{"This command is only available in direct messages."}
+	if diff := cmp.Diff(msg.posts, want); diff != "" {
+		t.Errorf("PostDirectOnly() posts differ: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_Router_HelpCommands(t *testing.T) {
+	t.Parallel()
+
+	want := []*plugin.Command{
+		{
+			Command:     "test add <name> <count> [<memo>]",
+			Description: "Add a new item.",
+			Args:        []*plugin.CommandArg{{Name: "name"}, {Name: "count"}, {Name: "memo", Rest: true}},
+		},
+		{
+			Command:     "test schedule <fields> <ratio>",
+			Description: "Set a schedule.",
+			Args:        []*plugin.CommandArg{{Name: "fields", Arity: 3}, {Name: "ratio"}},
+		},
+		{
+			Command:     "test post [--channel=<channel>] [--silent] <text>",
+			Description: "Post a message.",
+			Args:        []*plugin.CommandArg{{Name: "text"}},
+		},
+		{
+			Command:     "test config get <key>",
+			Description: "Get a config.",
+			Args:        []*plugin.CommandArg{{Name: "key"}},
+		},
+		{
+			Command:     "test config set <key> <value>",
+			Description: "Set a config.",
+			Args:        []*plugin.CommandArg{{Name: "key"}, {Name: "value"}},
+		},
+		{
+			Command:     "test secret set <key> <value>",
+			Description: "Set a secret.",
+			DirectOnly:  true,
+			Args:        []*plugin.CommandArg{{Name: "key"}, {Name: "value"}},
+		},
+	}
+
+	if diff := cmp.Diff(testRouter.HelpCommands(), want); diff != "" {
+		t.Errorf("Router.HelpCommands() differs: (-got +want)\n%s", diff)
+	}
+}
@@ -0,0 +1,80 @@
+package command_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kechako/gopher-bot/v2/bottest"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
+)
+
+// secretPlugin is a plugin that has a command only available in direct messages,
+// and handles the errors of the router like the plugins of this module.
+type secretPlugin struct {
+	router *command.Router
+}
+
+func newSecretPlugin() *secretPlugin {
+	return &secretPlugin{
+		router: command.New("secret", &command.Command{
+			Name:        "set",
+			Args:        []*command.Arg{{Name: "key"}, {Name: "value"}},
+			Description: "Set a secret.",
+			DirectOnly:  true,
+			Handler: func(ctx context.Context, req *command.Request) (string, error) {
+				return "Success to set a secret : " + req.String("key"), nil
+			},
+		}),
+	}
+}
+
+func (p *secretPlugin) Hello(ctx context.Context, hello plugin.Hello) {}
+
+func (p *secretPlugin) DoAction(ctx context.Context, msg plugin.Message) {
+	retMsg, err := p.router.Execute(ctx, msg)
+	if err != nil {
+		switch {
+		case errors.Is(err, command.ErrNotMatched):
+		case errors.Is(err, command.ErrInvalidSyntax):
+			msg.PostHelp(p.Help(ctx))
+		case errors.Is(err, command.ErrDirectOnly):
+			command.PostDirectOnly(msg)
+		}
+		return
+	}
+
+	msg.Post(retMsg)
+}
+
+func (p *secretPlugin) Help(ctx context.Context) *plugin.Help {
+	return &plugin.Help{
+		Name:     "secret",
+		Commands: p.router.HelpCommands(),
+	}
+}
+
+func Test_PostDirectOnly(t *testing.T) {
+	h := bottest.New(t)
+	h.AddPlugin(newSecretPlugin())
+	h.Start()
+
+	got := h.Send("general", "alice", "secret set token abc")
+	want := []*bottest.Post{
+		{ID: "1", ChannelID: "general", EphemeralTo: "alice", Text: "This command is only available in direct messages."},
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("posts differ: (-got +want)\n%s", diff)
+	}
+}
+
+func Test_PostDirectOnly_transcript(t *testing.T) {
+	h := bottest.New(t)
+	h.AddPlugin(newSecretPlugin())
+	h.Start()
+
+	h.RunTranscript("testdata/direct_only.txt")
+}
@@ -59,15 +59,18 @@ func (s *indentSpacer) grow(count int) {
 }
 
 var DefaultHelpFormatter = &HelpFormatter{
-	NameSuffix:    ": ",
-	CommandSuffix: ": ",
-	Indent:        4,
+	NameSuffix:       ": ",
+	CommandSuffix:    ": ",
+	DirectOnlySuffix: " (DM only)",
+	Indent:           4,
 }
 
 type HelpFormatter struct {
 	NameSuffix    string
 	CommandSuffix string
-	Indent        int
+	// DirectOnlySuffix is appended to commands that are only available in direct messages.
+	DirectOnlySuffix string
+	Indent           int
 }
 
 func (f *HelpFormatter) Format(help *Help) string {
@@ -97,7 +100,7 @@ func (f *HelpFormatter) format(w io.StringWriter, help *Help) {
 	var maxCmdWidth int
 	cmdSuffixWidth := runewidth.StringWidth(f.CommandSuffix)
 	for _, cmd := range help.Commands {
-		cmdWidth := runewidth.StringWidth(cmd.Command) + cmdSuffixWidth
+		cmdWidth := runewidth.StringWidth(f.command(cmd)) + cmdSuffixWidth
 		if cmdWidth > maxCmdWidth {
 			maxCmdWidth = cmdWidth
 		}
@@ -107,7 +110,7 @@ func (f *HelpFormatter) format(w io.StringWriter, help *Help) {
 	for _, cmd := range help.Commands {
 		w.WriteString("\n")
 		writeSpaces(w, f.Indent)
-		w.WriteString(runewidth.FillRight(cmd.Command+f.CommandSuffix, maxCmdWidth))
+		w.WriteString(runewidth.FillRight(f.command(cmd)+f.CommandSuffix, maxCmdWidth))
 
 		cmdDesc := bufio.NewScanner(strings.NewReader(cmd.Description))
 		if cmdDesc.Scan() {
@@ -122,6 +125,13 @@ func (f *HelpFormatter) format(w io.StringWriter, help *Help) {
 	}
 }
 
+func (f *HelpFormatter) command(cmd *Command) string {
+	if cmd.DirectOnly {
+		return cmd.Command + f.DirectOnlySuffix
+	}
+	return cmd.Command
+}
+
 func writeSpaces(w io.StringWriter, width int) {
 	if ww, ok := w.(io.Writer); ok {
 		defaultIndentSpacer.WriteTo(ww, width)
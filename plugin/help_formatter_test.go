@@ -167,15 +167,36 @@ third line`,
                   second line
                   third line`,
 	},
+	{
+		help: &Help{
+			Name:        "test07",
+			Description: "direct only",
+			Commands: []*Command{
+				{
+					Command:     "command01",
+					Description: "public",
+				},
+				{
+					Command:     "command02",
+					Description: "private",
+					DirectOnly:  true,
+				},
+			},
+		},
+		doc: `test07: direct only
+    command01=>      public
+    command02 [DM]=> private`,
+	},
 }
 
 func Test_HelpFormatter(t *testing.T) {
 	t.Parallel()
 
 	formatter := &HelpFormatter{
-		NameSuffix:    ": ",
-		CommandSuffix: "=> ",
-		Indent:        4,
+		NameSuffix:       ": ",
+		CommandSuffix:    "=> ",
+		DirectOnlySuffix: " [DM]",
+		Indent:           4,
 	}
 
 	for _, tt := range helps {
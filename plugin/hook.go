@@ -0,0 +1,29 @@
+package plugin
+
+import "time"
+
+//go:generate stringer -type=Hook -linecomment
+
+// Hook represents a plugin method called by the bot.
+type Hook int
+
+const (
+	HelloHook          Hook = iota // Hello
+	DoActionHook                   // DoAction
+	HelpHook                       // Help
+	ConnectedHook                  // Connected
+	DisconnectedHook               // Disconnected
+	DeliveryFailedHook             // DeliveryFailed
+	ReactionHook                   // HandleReaction
+	MessageEditedHook              // MessageEdited
+	MessageDeletedHook             // MessageDeleted
+	InteractionHook                // HandleInteraction
+)
+
+// TimeoutProvider is the interface implemented by plugins that need
+// timeouts other than the bot default.
+type TimeoutProvider interface {
+	// Timeout returns the timeout for the hook.
+	// Returns zero to use the default timeout of the bot.
+	Timeout(hook Hook) time.Duration
+}
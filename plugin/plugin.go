@@ -3,7 +3,10 @@ package plugin
 
 import (
 	"context"
+	"io"
 	"log/slog"
+
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
 )
 
 // Plugin is the interface implemented by bot plugins.
@@ -16,6 +19,96 @@ type Plugin interface {
 	Help(ctx context.Context) *Help
 }
 
+// ConnectionObserver is the interface implemented by plugins that need to know
+// when the service connects to or disconnects from the chat platform.
+type ConnectionObserver interface {
+	// Connected is called each time the service has connected, including reconnects.
+	// It is called after Hello on the first connection.
+	Connected(ctx context.Context, h Hello)
+	// Disconnected is called when the service has disconnected.
+	Disconnected(ctx context.Context)
+}
+
+// DeliveryFailure describes a message that the service has failed to deliver.
+type DeliveryFailure struct {
+	// ChannelID is the ID of the channel that the message was posted to.
+	ChannelID string
+	// Text is the text of the message.
+	Text string
+	// Err is the reason of the failure.
+	Err error
+}
+
+// DeliveryObserver is the interface implemented by plugins that need to know
+// messages that the service has given up delivering.
+type DeliveryObserver interface {
+	// DeliveryFailed is called when the service has given up delivering a message,
+	// after retries or because of an error that cannot be fixed by retrying.
+	DeliveryFailed(ctx context.Context, f *DeliveryFailure)
+}
+
+// Reaction is the interface that represents a reaction added to or removed from a message.
+type Reaction interface {
+	// UserID returns ID of the user that reacted.
+	UserID() string
+	// Emoji returns the emoji of the reaction.
+	// On Slack, it is the name of the emoji without colons, e.g. "+1".
+	// On Discord, it is the Unicode emoji, or name:id for custom emojis.
+	Emoji() string
+	// Target returns the reference to the message that the reaction is added to.
+	Target() MessageRef
+	// Added returns true if the reaction is added, false if it is removed.
+	Added() bool
+}
+
+// ReactionHandler is the interface implemented by plugins that handle reactions.
+type ReactionHandler interface {
+	// HandleReaction is called when a reaction is added to or removed from a message.
+	// Reactions of the bot itself are not passed.
+	HandleReaction(ctx context.Context, r Reaction)
+}
+
+// EditHandler is the interface implemented by plugins that handle messages edited by users.
+// Plugins can run commands again on edit by calling DoAction with the edited message.
+type EditHandler interface {
+	// MessageEdited is called when a user has edited a message.
+	// m has the edited text.
+	MessageEdited(ctx context.Context, m Message)
+}
+
+// DeleteHandler is the interface implemented by plugins that handle messages deleted by users.
+type DeleteHandler interface {
+	// MessageDeleted is called when a user has deleted a message.
+	MessageDeleted(ctx context.Context, ref MessageRef)
+}
+
+// Interaction is the interface that represents a click of a button, or a selection of
+// a select menu, in a rich message posted by the bot.
+type Interaction interface {
+	// UserID returns ID of the user that interacted.
+	UserID() string
+	// ActionID returns the action ID of the button or the select menu.
+	ActionID() string
+	// Value returns the value of the button, or the value of the selected option.
+	Value() string
+	// Target returns the reference to the message that contains the button or the select menu.
+	Target() MessageRef
+	// Update replaces the message that contains the button or the select menu.
+	Update(msg *rich.Message) error
+	// PostEphemeral posts a new message that is visible only to the user that interacted.
+	PostEphemeral(text string)
+}
+
+// InteractionHandler is the interface implemented by plugins that handle interactions
+// with buttons and select menus that they have posted.
+type InteractionHandler interface {
+	// ActionIDPrefixes returns the prefixes of the action IDs that the plugin handles.
+	// An interaction is passed to the plugin that has the longest prefix of its action ID.
+	ActionIDPrefixes() []string
+	// HandleInteraction is called when a user has interacted with a rich message.
+	HandleInteraction(ctx context.Context, i Interaction)
+}
+
 // Hello is the interface to get bot information.
 type Hello interface {
 	Bot() Bot
@@ -39,6 +132,87 @@ type Bot interface {
 	User(userID string) User
 }
 
+// ContextPoster is the interface implemented by Bot of the services that can report
+// whether messages are delivered.
+// Plugins can check whether the Bot implements it with a type assertion.
+type ContextPoster interface {
+	// PostContext posts a new message to the channel, and waits until it is delivered.
+	// Returns the reference to the posted message.
+	PostContext(ctx context.Context, channelID string, text string) (MessageRef, error)
+	// MentionContext posts a new message that mentions to the user to the channel,
+	// and waits until it is delivered.
+	// Returns the reference to the posted message.
+	MentionContext(ctx context.Context, channelID, userID, text string) (MessageRef, error)
+	// ProcessCommandContext processes the specified command on the channel.
+	// Returns an error if the command cannot be passed to the bot before ctx is done.
+	ProcessCommandContext(ctx context.Context, channelID string, command string) error
+}
+
+// Editor is the interface implemented by Bot of the services that can edit and delete
+// messages posted by the bot.
+// Plugins can check whether the Bot implements it with a type assertion.
+type Editor interface {
+	// Edit replaces the text of the message posted by the bot.
+	Edit(ref MessageRef, text string) error
+	// Delete deletes the message posted by the bot.
+	Delete(ref MessageRef) error
+}
+
+// Reactor is the interface implemented by Bot and Message of the services
+// that can add reactions to messages.
+// Plugins can check whether the Bot or the Message implements it with a type assertion.
+type Reactor interface {
+	// React adds a reaction of the emoji to the message.
+	React(ref MessageRef, emoji string) error
+}
+
+// ThreadPoster is the interface implemented by Bot of the services that support threads.
+// Plugins can check whether the Bot implements it with a type assertion.
+type ThreadPoster interface {
+	// PostToThread posts a new message to the thread of the channel.
+	// If threadID is empty, the message is posted to the channel.
+	PostToThread(channelID, threadID, text string)
+	// ProcessThreadCommand processes the specified command on the thread of the channel.
+	// Messages posted in reply to the command are posted to the thread.
+	ProcessThreadCommand(channelID, threadID, command string)
+}
+
+// DirectMessenger is the interface implemented by Bot of the services that can send
+// direct messages.
+// Plugins can check whether the Bot implements it with a type assertion.
+type DirectMessenger interface {
+	// DirectMessage posts a new message to the direct message channel with the user.
+	DirectMessage(userID, text string) error
+}
+
+// RichPoster is the interface implemented by Bot of the services that can post rich messages.
+// Plugins can check whether the Bot implements it with a type assertion,
+// or post msg.PlainText() with Bot.Post instead.
+type RichPoster interface {
+	// PostRich posts a new rich message to the channel.
+	// Parts of the message that the service does not support are posted as plain text.
+	PostRich(channelID string, msg *rich.Message)
+	// PostRichContext is like PostRich, but waits until the message is delivered.
+	// Returns the reference to the posted message.
+	PostRichContext(ctx context.Context, channelID string, msg *rich.Message) (MessageRef, error)
+}
+
+// Uploader is the interface implemented by Bot of the services that can upload files.
+// Plugins can check whether the Bot implements it with a type assertion.
+type Uploader interface {
+	// Upload uploads the content of r as a file named name to the channel.
+	// comment is posted with the file, if it is not empty.
+	Upload(channelID, name string, r io.Reader, comment string) error
+}
+
+// MessageRef is a reference to a message posted to a channel.
+type MessageRef struct {
+	// ChannelID is the ID of the channel that the message was posted to.
+	ChannelID string
+	// MessageID is the ID of the message. On Slack, it is the timestamp of the message.
+	MessageID string
+}
+
 // Channel is the interface that represents a chanel.
 type Channel interface {
 	// ID is an ID of the channel.
@@ -59,6 +233,47 @@ type User interface {
 	DisplayName() string
 }
 
+// Profile is the interface implemented by User of the services that have profiles of users.
+// Plugins can check whether the User implements it with a type assertion.
+type Profile interface {
+	// AvatarURL is a URL of the avatar image of the user.
+	// It is empty if the user does not have an avatar.
+	AvatarURL() string
+	// IsBot returns whether the user is a bot.
+	IsBot() bool
+}
+
+// Member is the interface that represents a user as a member of a guild, such as a Discord server.
+// DisplayName of a member is the nickname in the guild if it is set.
+type Member interface {
+	User
+	// Nickname is a nickname of the user in the guild. It is empty if it is not set.
+	Nickname() string
+	// Roles returns names of the roles of the user in the guild.
+	Roles() []string
+}
+
+// MemberFinder is the interface implemented by Bot of the services that have guilds.
+// Plugins can check whether the Bot implements it with a type assertion.
+type MemberFinder interface {
+	// Member returns a member of the guild of the channel of specified channelID.
+	// Returns nil if the channel is not in a guild, or the user is not found.
+	Member(channelID, userID string) Member
+}
+
+// Attachment is the interface that represents a file attached to a message.
+type Attachment interface {
+	// Name returns the file name.
+	Name() string
+	// MIMEType returns the MIME type of the file, e.g. "text/csv".
+	MIMEType() string
+	// Size returns the size of the file in bytes.
+	Size() int64
+	// Open downloads the content of the file.
+	// The caller must close the returned reader.
+	Open(ctx context.Context) (io.ReadCloser, error)
+}
+
 // Bot is the interface that represents a service message.
 type Message interface {
 	// ChannelID returns ID of the channel that the message was posted.
@@ -67,10 +282,12 @@ type Message interface {
 	UserID() string
 	// Text is a text of the message.
 	Text() string
-	// Post posts a new message to the channel that the message was posted.
+	// Post posts a new message to where the message was posted,
+	// that is the thread if the message is in a thread on the services that support threads,
+	// otherwise the channel.
 	Post(text string)
 	// Mention posts a new message that mentions to the user that posted the message,
-	// to the channel that the message was posted.
+	// to where the message was posted.
 	Mention(text string)
 	// Mentions returns user IDs that message mentions to.
 	Mentions() []string
@@ -81,6 +298,70 @@ type Message interface {
 	PostHelp(help *Help)
 }
 
+// ContextReplier is the interface implemented by Message of the services that can report
+// whether replies are delivered.
+// Plugins can check whether the Message implements it with a type assertion.
+type ContextReplier interface {
+	// PostContext is like Message.Post, but waits until the message is delivered.
+	// Returns the reference to the posted message.
+	PostContext(ctx context.Context, text string) (MessageRef, error)
+	// MentionContext is like Message.Mention, but waits until the message is delivered.
+	// Returns the reference to the posted message.
+	MentionContext(ctx context.Context, text string) (MessageRef, error)
+}
+
+// ThreadReplier is the interface implemented by Message of the services that support threads.
+// Plugins can check whether the Message implements it with a type assertion.
+type ThreadReplier interface {
+	// ThreadID returns ID of the thread that the message was posted in.
+	// Returns an empty string if the message is not in a thread.
+	ThreadID() string
+	// ReplyInThread posts a new message to the thread of the message.
+	// If the message is not in a thread, a new thread is started from the message
+	// if possible.
+	ReplyInThread(text string)
+	// PostToChannel posts a new message to the channel that the message was posted,
+	// outside of the thread.
+	PostToChannel(text string)
+}
+
+// DirectChecker is the interface implemented by Message of the services that have
+// direct message channels.
+// Plugins can check whether the Message implements it with a type assertion.
+type DirectChecker interface {
+	// IsDirect returns whether the message was posted in a direct message channel.
+	IsDirect() bool
+}
+
+// EphemeralReplier is the interface implemented by Message of the services that can post
+// messages visible only to a user.
+// Plugins can check whether the Message implements it with a type assertion.
+type EphemeralReplier interface {
+	// PostEphemeral posts a new message to where the message was posted,
+	// that is visible only to the user that posted the message.
+	// If the message cannot be posted ephemerally, e.g. it is not in a channel,
+	// it is sent as a direct message.
+	PostEphemeral(text string)
+}
+
+// RichReplier is the interface implemented by Message of the services that can post
+// rich messages.
+// Plugins can check whether the Message implements it with a type assertion,
+// or post msg.PlainText() with Message.Post instead.
+type RichReplier interface {
+	// PostRich posts a new rich message to where the message was posted.
+	// Parts of the message that the service does not support are posted as plain text.
+	PostRich(msg *rich.Message)
+}
+
+// AttachmentProvider is the interface implemented by Message of the services that
+// support files attached to messages.
+// Plugins can check whether the Message implements it with a type assertion.
+type AttachmentProvider interface {
+	// Attachments returns the files attached to the message.
+	Attachments() []Attachment
+}
+
 // Help represents a help information of a plugin.
 type Help struct {
 	Name        string
@@ -96,4 +377,20 @@ func (h *Help) String() string {
 type Command struct {
 	Command     string
 	Description string
+	// DirectOnly indicates the command is only available in direct messages.
+	DirectOnly bool
+	// Args are the arguments of the command, in the order of the usage in Command.
+	// It is optional, and used by services that build commands from helps,
+	// such as Discord application commands.
+	Args []*CommandArg
+}
+
+// CommandArg represents an argument of a command.
+type CommandArg struct {
+	// Name is the name of the argument, e.g. "name" of "<name>".
+	Name string
+	// Arity is a number of words that the argument takes. Zero means one word.
+	Arity int
+	// Rest indicates the argument takes the rest of the line as it is.
+	Rest bool
 }
@@ -0,0 +1,240 @@
+// Package rich provides a service-neutral model of rich messages.
+//
+// A Message is composed of blocks, and each service renders them to its own format,
+// such as Block Kit on Slack and embeds on Discord. Services that do not support
+// rich messages post Message.PlainText instead.
+//
+//	msg := rich.New("Weather report").
+//		Section("Tokyo", "Sunny").
+//		Fields(&rich.Field{Name: "High", Value: "25℃"}, &rich.Field{Name: "Low", Value: "18℃"}).
+//		Context("via weather plugin")
+package rich
+
+import (
+	"strings"
+)
+
+//go:generate stringer -type=BlockType -linecomment
+
+// BlockType represents a type of Block.
+type BlockType int
+
+const (
+	SectionBlock BlockType = iota // section
+	FieldsBlock                   // fields
+	CodeBlock                     // code
+	ImageBlock                    // image
+	ContextBlock                  // context
+	ActionsBlock                  // actions
+	DividerBlock                  // divider
+	SelectBlock                   // select
+)
+
+// Block represents a block of a rich message.
+// Fields used by each type are noted on them.
+type Block struct {
+	Type BlockType
+	// Title is the title of the section. SectionBlock.
+	Title string
+	// Text is the text of the block. SectionBlock, CodeBlock, ContextBlock,
+	// the alternative text of ImageBlock, and the placeholder of SelectBlock.
+	Text string
+	// Language is the language of the code for syntax highlighting. CodeBlock.
+	Language string
+	// URL is the URL of the image. ImageBlock.
+	URL string
+	// Fields are name and value pairs. FieldsBlock.
+	Fields []*Field
+	// Buttons are buttons. ActionsBlock.
+	Buttons []*Button
+	// ActionID identifies the action of the select menu. SelectBlock.
+	ActionID string
+	// Options are options of the select menu. SelectBlock.
+	Options []*Option
+}
+
+// Field represents a name and value pair.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// ButtonStyle represents a style of Button.
+type ButtonStyle int
+
+const (
+	DefaultButton ButtonStyle = iota
+	PrimaryButton
+	DangerButton
+)
+
+// Button represents a button.
+type Button struct {
+	// ActionID identifies the action of the button.
+	ActionID string
+	// Label is the label of the button.
+	Label string
+	// Value is passed with the action when the button is clicked.
+	Value string
+	// URL is the URL opened when the button is clicked.
+	// Buttons with URL do not cause actions.
+	URL   string
+	Style ButtonStyle
+}
+
+// Option represents an option of a select menu.
+type Option struct {
+	// Label is the label of the option.
+	Label string
+	// Value is passed with the action when the option is selected.
+	Value string
+}
+
+// Message represents a rich message.
+type Message struct {
+	// Text is the text for notifications, and the fallback for services without rich messages
+	// when there are no blocks.
+	Text   string
+	Blocks []*Block
+}
+
+// New returns a new *Message with the fallback text.
+func New(text string) *Message {
+	return &Message{
+		Text: text,
+	}
+}
+
+// Section appends a section block. title can be empty.
+func (m *Message) Section(title, text string) *Message {
+	return m.append(&Block{Type: SectionBlock, Title: title, Text: text})
+}
+
+// Fields appends a fields block.
+func (m *Message) Fields(fields ...*Field) *Message {
+	return m.append(&Block{Type: FieldsBlock, Fields: fields})
+}
+
+// Code appends a code block. language can be empty.
+func (m *Message) Code(language, code string) *Message {
+	return m.append(&Block{Type: CodeBlock, Language: language, Text: code})
+}
+
+// Image appends an image block.
+func (m *Message) Image(url, altText string) *Message {
+	return m.append(&Block{Type: ImageBlock, URL: url, Text: altText})
+}
+
+// Context appends a context block, that is shown as a footer.
+func (m *Message) Context(text string) *Message {
+	return m.append(&Block{Type: ContextBlock, Text: text})
+}
+
+// Buttons appends an actions block of the buttons.
+func (m *Message) Buttons(buttons ...*Button) *Message {
+	return m.append(&Block{Type: ActionsBlock, Buttons: buttons})
+}
+
+// Select appends a select menu block of the options. placeholder can be empty.
+func (m *Message) Select(actionID, placeholder string, options ...*Option) *Message {
+	return m.append(&Block{Type: SelectBlock, ActionID: actionID, Text: placeholder, Options: options})
+}
+
+// Divider appends a divider block.
+func (m *Message) Divider() *Message {
+	return m.append(&Block{Type: DividerBlock})
+}
+
+func (m *Message) append(b *Block) *Message {
+	m.Blocks = append(m.Blocks, b)
+	return m
+}
+
+// PlainText returns the message degraded to plain text.
+// Returns Text if there are no blocks.
+func (m *Message) PlainText() string {
+	if len(m.Blocks) == 0 {
+		return m.Text
+	}
+
+	lines := make([]string, 0, len(m.Blocks))
+	for _, b := range m.Blocks {
+		if text := b.PlainText(); text != "" {
+			lines = append(lines, text)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// PlainText returns the block degraded to plain text.
+func (b *Block) PlainText() string {
+	var s strings.Builder
+
+	switch b.Type {
+	case SectionBlock:
+		if b.Title != "" {
+			s.WriteString(b.Title)
+			if b.Text != "" {
+				s.WriteString("\n")
+			}
+		}
+		s.WriteString(b.Text)
+	case FieldsBlock:
+		for i, f := range b.Fields {
+			if i > 0 {
+				s.WriteString("\n")
+			}
+			s.WriteString(f.Name)
+			s.WriteString(": ")
+			s.WriteString(f.Value)
+		}
+	case CodeBlock:
+		s.WriteString("```")
+		s.WriteString(b.Language)
+		s.WriteString("\n")
+		s.WriteString(b.Text)
+		if !strings.HasSuffix(b.Text, "\n") {
+			s.WriteString("\n")
+		}
+		s.WriteString("```")
+	case ImageBlock:
+		if b.Text != "" {
+			s.WriteString(b.Text)
+			s.WriteString(": ")
+		}
+		s.WriteString(b.URL)
+	case ContextBlock:
+		s.WriteString(b.Text)
+	case ActionsBlock:
+		for i, btn := range b.Buttons {
+			if i > 0 {
+				s.WriteString(" ")
+			}
+			s.WriteString("[")
+			s.WriteString(btn.Label)
+			if btn.URL != "" {
+				s.WriteString(" ")
+				s.WriteString(btn.URL)
+			}
+			s.WriteString("]")
+		}
+	case DividerBlock:
+		s.WriteString("---")
+	case SelectBlock:
+		if b.Text != "" {
+			s.WriteString(b.Text)
+			s.WriteString(": ")
+		}
+		for i, o := range b.Options {
+			if i > 0 {
+				s.WriteString(" ")
+			}
+			s.WriteString("[")
+			s.WriteString(o.Label)
+			s.WriteString("]")
+		}
+	}
+
+	return s.String()
+}
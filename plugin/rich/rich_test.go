@@ -0,0 +1,91 @@
+package rich
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var plainTextTests = map[string]struct {
+	msg  *Message
+	text string
+}{
+	"no blocks": {
+		msg:  New("fallback"),
+		text: "fallback",
+	},
+	"section": {
+		msg:  New("fallback").Section("Title", "text").Section("", "no title"),
+		text: "Title\ntext\nno title",
+	},
+	"fields": {
+		msg: New("fallback").Fields(
+			&Field{Name: "High", Value: "25"},
+			&Field{Name: "Low", Value: "18"},
+		),
+		text: "High: 25\nLow: 18",
+	},
+	"code": {
+		msg:  New("fallback").Code("go", "fmt.Println()").Code("", "ls\n"),
+		text: "```go\nfmt.Println()\n```\n```\nls\n```",
+	},
+	"image": {
+		msg:  New("fallback").Image("https://example.com/a.png", "graph").Image("https://example.com/b.png", ""),
+		text: "graph: https://example.com/a.png\nhttps://example.com/b.png",
+	},
+	"context and divider": {
+		msg:  New("fallback").Section("", "text").Divider().Context("footer"),
+		text: "text\n---\nfooter",
+	},
+	"buttons": {
+		msg: New("fallback").Buttons(
+			&Button{ActionID: "ok", Label: "OK", Style: PrimaryButton},
+			&Button{Label: "Docs", URL: "https://example.com"},
+		),
+		text: "[OK] [Docs https://example.com]",
+	},
+	"select": {
+		msg: New("fallback").Select("color", "Pick a color",
+			&Option{Label: "Red", Value: "red"},
+			&Option{Label: "Blue", Value: "blue"},
+		),
+		text: "Pick a color: [Red] [Blue]",
+	},
+}
+
+func Test_Message_PlainText(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range plainTextTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			text := tt.msg.PlainText()
+			if diff := cmp.Diff(tt.text, text); diff != "" {
+				t.Errorf("Message.PlainText() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Message_builder(t *testing.T) {
+	t.Parallel()
+
+	msg := New("report").
+		Section("Title", "text").
+		Fields(&Field{Name: "name", Value: "value"}).
+		Context("footer")
+
+	want := &Message{
+		Text: "report",
+		Blocks: []*Block{
+			{Type: SectionBlock, Title: "Title", Text: "text"},
+			{Type: FieldsBlock, Fields: []*Field{{Name: "name", Value: "value"}}},
+			{Type: ContextBlock, Text: "footer"},
+		},
+	}
+	if diff := cmp.Diff(want, msg); diff != "" {
+		t.Errorf("builder mismatch (-want +got):\n%s", diff)
+	}
+}
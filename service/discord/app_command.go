@@ -0,0 +1,340 @@
+package discord
+
+import (
+	"strings"
+	"unicode"
+
+	discord "github.com/bwmarrin/discordgo"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/util"
+)
+
+const (
+	// maxCommands is the maximum number of application commands.
+	maxCommands = 100
+	// maxCommandOptions is the maximum number of options or sub commands of a command.
+	maxCommandOptions = 25
+	// maxCommandName is the maximum length of names of commands and options.
+	maxCommandName = 32
+	// maxCommandDescription is the maximum length of descriptions of commands and options.
+	maxCommandDescription = 100
+)
+
+// argumentsParam is the parameter of commands that cannot be parsed,
+// that takes the arguments as they are.
+var argumentsParam = &commandParam{name: "arguments", word: "arguments", raw: true}
+
+// commandSpec represents an application command generated from plugin commands.
+//
+// A plugin command "cron add [--thread=<thread>] <name> <schedule> <command>" is
+// generated to the sub command "add" of the command "cron", that has options
+// "name", "schedule", "command" and "thread".
+type commandSpec struct {
+	// name is the name of the application command.
+	name string
+	// word is the word of the plugin command.
+	word        string
+	description string
+	subcommands []*commandSpec
+	// params are the parameters in the order of the plugin command.
+	params []*commandParam
+}
+
+// commandParam represents an option of an application command.
+type commandParam struct {
+	// name is the name of the option.
+	name string
+	// word is the name of the flag, or the placeholder of the argument.
+	word string
+	// flag indicates the parameter is a flag such as [--thread=<thread>].
+	flag bool
+	// boolean indicates the flag does not take a value such as [--force].
+	boolean  bool
+	required bool
+	// raw indicates the value is put in the text as it is, without quoting.
+	raw bool
+}
+
+// commandSpecs generates application commands from the commands of the helps.
+func commandSpecs(helps []*plugin.Help) []*commandSpec {
+	var specs []*commandSpec
+	find := func(word string) *commandSpec {
+		for _, spec := range specs {
+			if spec.word == word {
+				return spec
+			}
+		}
+		return nil
+	}
+
+	for _, help := range helps {
+		for _, cmd := range help.Commands {
+			words := splitCommand(cmd.Command)
+			if len(words) == 0 {
+				continue
+			}
+
+			spec := find(words[0])
+			if spec == nil {
+				if len(specs) >= maxCommands {
+					continue
+				}
+				spec = &commandSpec{
+					name:        commandName(words[0]),
+					word:        words[0],
+					description: commandDescription(help.Description, help.Name),
+				}
+				specs = append(specs, spec)
+			}
+
+			words = words[1:]
+			if len(words) > 0 && isLiteral(words[0]) {
+				if spec.subcommand(words[0]) == nil && len(spec.subcommands) < maxCommandOptions {
+					spec.subcommands = append(spec.subcommands, &commandSpec{
+						name:        commandName(words[0]),
+						word:        words[0],
+						description: commandDescription(cmd.Description, cmd.Command),
+						params:      commandParams(words[1:], cmd.Args),
+					})
+				}
+				continue
+			}
+
+			spec.params = commandParams(words, cmd.Args)
+		}
+	}
+
+	return specs
+}
+
+// splitCommand splits the command into words, keeping optional parameters
+// such as "[--name <value>]" in one word.
+func splitCommand(command string) []string {
+	var words []string
+	for _, w := range strings.Fields(command) {
+		if n := len(words); n > 0 && strings.HasPrefix(words[n-1], "[") && !strings.HasSuffix(words[n-1], "]") {
+			words[n-1] += " " + w
+			continue
+		}
+		words = append(words, w)
+	}
+
+	return words
+}
+
+// isLiteral returns whether the word is a literal, not a parameter.
+func isLiteral(word string) bool {
+	return !strings.HasPrefix(word, "<") && !strings.HasPrefix(word, "[") && !strings.HasPrefix(word, "-")
+}
+
+// commandParams returns the parameters of the words.
+// Returns argumentsParam if the words cannot be parsed.
+//
+// Arguments that take more than one word or the rest of the line are put in the text
+// as they are, and the other arguments are quoted.
+// If args is empty, the last argument is put as it is, because it is often the rest of the line.
+func commandParams(words []string, args []*plugin.CommandArg) []*commandParam {
+	params := make([]*commandParam, 0, len(words))
+	for _, w := range words {
+		required := true
+		if strings.HasPrefix(w, "[") && strings.HasSuffix(w, "]") {
+			required = false
+			w = w[1 : len(w)-1]
+		}
+
+		var p *commandParam
+		switch {
+		case strings.HasPrefix(w, "-"):
+			name, value, _ := strings.Cut(strings.TrimLeft(w, "-"), "=")
+			name, _, hasValue := strings.Cut(name, " ")
+			p = &commandParam{
+				word:    name,
+				flag:    true,
+				boolean: !hasValue && value == "",
+			}
+		case strings.HasPrefix(w, "<"):
+			p = &commandParam{
+				word:     strings.Trim(w, "<>."),
+				required: required,
+			}
+		default:
+			return []*commandParam{argumentsParam}
+		}
+		p.name = commandName(p.word)
+		params = append(params, p)
+
+		if len(params) >= maxCommandOptions {
+			break
+		}
+	}
+
+	if len(args) == 0 {
+		for i := len(params) - 1; i >= 0; i-- {
+			if !params[i].flag {
+				params[i].raw = true
+				break
+			}
+		}
+		return params
+	}
+
+	for _, p := range params {
+		if p.flag {
+			continue
+		}
+		for _, arg := range args {
+			if arg.Name == p.word {
+				p.raw = arg.Arity > 1 || arg.Rest
+				break
+			}
+		}
+	}
+
+	return params
+}
+
+// commandName converts the word to a name of commands and options.
+// Names must be lower case, and consist of letters, numbers, - and _.
+func commandName(word string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsNumber(r), r == '-', r == '_':
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, word)
+
+	return truncate(name, maxCommandName)
+}
+
+// commandDescription returns the description, or fallback if it is empty.
+func commandDescription(description, fallback string) string {
+	if description == "" {
+		description = fallback
+	}
+	if description == "" {
+		description = "-"
+	}
+
+	return truncate(description, maxCommandDescription)
+}
+
+// subcommand returns the sub command of the word.
+func (c *commandSpec) subcommand(word string) *commandSpec {
+	for _, sub := range c.subcommands {
+		if sub.word == word {
+			return sub
+		}
+	}
+
+	return nil
+}
+
+// subcommandByName returns the sub command of the name.
+func (c *commandSpec) subcommandByName(name string) *commandSpec {
+	for _, sub := range c.subcommands {
+		if sub.name == name {
+			return sub
+		}
+	}
+
+	return nil
+}
+
+// applicationCommand returns the application command to register.
+// Commands with sub commands cannot be run without sub commands on Discord,
+// so parameters of the command itself are dropped.
+func (c *commandSpec) applicationCommand() *discord.ApplicationCommand {
+	cmd := &discord.ApplicationCommand{
+		Type:        discord.ChatApplicationCommand,
+		Name:        c.name,
+		Description: c.description,
+	}
+
+	if len(c.subcommands) == 0 {
+		cmd.Options = c.options()
+		return cmd
+	}
+
+	for _, sub := range c.subcommands {
+		cmd.Options = append(cmd.Options, &discord.ApplicationCommandOption{
+			Type:        discord.ApplicationCommandOptionSubCommand,
+			Name:        sub.name,
+			Description: sub.description,
+			Options:     sub.options(),
+		})
+	}
+
+	return cmd
+}
+
+// options returns the options of the parameters.
+// Required options are listed first, because Discord requires it.
+func (c *commandSpec) options() []*discord.ApplicationCommandOption {
+	var required, optional []*discord.ApplicationCommandOption
+	for _, p := range c.params {
+		o := &discord.ApplicationCommandOption{
+			Type:        discord.ApplicationCommandOptionString,
+			Name:        p.name,
+			Description: p.word,
+			Required:    p.required,
+		}
+		if p.boolean {
+			o.Type = discord.ApplicationCommandOptionBoolean
+		}
+		if p.flag {
+			o.Description = "--" + p.word
+		}
+
+		if o.Required {
+			required = append(required, o)
+		} else {
+			optional = append(optional, o)
+		}
+	}
+
+	return append(required, optional...)
+}
+
+// text returns the text of the plugin command of the options.
+// Flags are put before the arguments, and arguments are quoted unless they are raw.
+func (c *commandSpec) text(options []*discord.ApplicationCommandInteractionDataOption) string {
+	words := []string{c.word}
+
+	spec := c
+	if len(options) > 0 && options[0].Type == discord.ApplicationCommandOptionSubCommand {
+		if sub := c.subcommandByName(options[0].Name); sub != nil {
+			words = append(words, sub.word)
+			spec = sub
+		}
+		options = options[0].Options
+	}
+
+	values := make(map[string]*discord.ApplicationCommandInteractionDataOption, len(options))
+	for _, o := range options {
+		values[o.Name] = o
+	}
+
+	var args []string
+	for _, p := range spec.params {
+		o, ok := values[p.name]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case p.boolean:
+			if o.BoolValue() {
+				words = append(words, "--"+p.word)
+			}
+		case p.flag:
+			words = append(words, "--"+p.word+"="+util.Quote(o.StringValue()))
+		case p.raw:
+			args = append(args, o.StringValue())
+		default:
+			args = append(args, util.Quote(o.StringValue()))
+		}
+	}
+
+	return strings.Join(append(words, args...), " ")
+}
@@ -0,0 +1,234 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	discord "github.com/bwmarrin/discordgo"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+	"github.com/kechako/gopher-bot/v2/service/internal/split"
+)
+
+// appCommandMessage is a plugin.Message of an application command.
+//
+// The response to the command is deferred when it is received, and the first message
+// posted in reply to it is the response. The other messages are followup messages.
+type appCommandMessage struct {
+	service     *discordService
+	interaction *discord.Interaction
+	userID      string
+	text        string
+
+	responded bool
+	mux       sync.Mutex
+}
+
+var (
+	_ plugin.Message            = (*appCommandMessage)(nil)
+	_ plugin.ContextReplier     = (*appCommandMessage)(nil)
+	_ plugin.Reactor            = (*appCommandMessage)(nil)
+	_ plugin.ThreadReplier      = (*appCommandMessage)(nil)
+	_ plugin.DirectChecker      = (*appCommandMessage)(nil)
+	_ plugin.EphemeralReplier   = (*appCommandMessage)(nil)
+	_ plugin.RichReplier        = (*appCommandMessage)(nil)
+	_ plugin.AttachmentProvider = (*appCommandMessage)(nil)
+)
+
+// newAppCommandMessage returns a new *appCommandMessage.
+func newAppCommandMessage(service *discordService, interaction *discord.Interaction, text string) *appCommandMessage {
+	return &appCommandMessage{
+		service:     service,
+		interaction: interaction,
+		userID:      interactionUserID(interaction),
+		text:        text,
+	}
+}
+
+// ChannelID implements the plugin.Message interface.
+func (m *appCommandMessage) ChannelID() string {
+	return m.interaction.ChannelID
+}
+
+// UserID implements the plugin.Message interface.
+func (m *appCommandMessage) UserID() string {
+	return m.userID
+}
+
+// IsDirect implements the plugin.DirectChecker interface.
+func (m *appCommandMessage) IsDirect() bool {
+	return m.interaction.GuildID == ""
+}
+
+// ThreadID implements the plugin.ThreadReplier interface.
+func (m *appCommandMessage) ThreadID() string {
+	ch, err := m.service.channel(m.ChannelID())
+	if err != nil {
+		m.service.l.Error("Failed to get channel info", slog.String("channel_id", m.ChannelID()), slog.Any("err", err))
+		return ""
+	}
+	if !ch.IsThread() {
+		return ""
+	}
+
+	return ch.ID
+}
+
+// Text implements the plugin.Message interface.
+func (m *appCommandMessage) Text() string {
+	return m.text
+}
+
+// Attachments implements the plugin.AttachmentProvider interface.
+// Commands do not have attachment options.
+func (m *appCommandMessage) Attachments() []plugin.Attachment {
+	return nil
+}
+
+// Post implements the plugin.Message interface.
+func (m *appCommandMessage) Post(text string) {
+	m.PostContext(context.Background(), text)
+}
+
+// Mention implements the plugin.Message interface.
+func (m *appCommandMessage) Mention(text string) {
+	m.MentionContext(context.Background(), text)
+}
+
+// ReplyInThread implements the plugin.ThreadReplier interface.
+// Responses to commands cannot start threads, so the message is posted.
+func (m *appCommandMessage) ReplyInThread(text string) {
+	m.Post(text)
+}
+
+// PostToChannel implements the plugin.ThreadReplier interface.
+func (m *appCommandMessage) PostToChannel(text string) {
+	m.Post(text)
+}
+
+// PostEphemeral implements the plugin.EphemeralReplier interface.
+func (m *appCommandMessage) PostEphemeral(text string) {
+	for _, chunk := range split.Text(text, maxMessageLength) {
+		if _, err := m.respond(context.Background(), &discord.WebhookParams{
+			Content: chunk,
+			Flags:   discord.MessageFlagsEphemeral,
+		}); err != nil {
+			return
+		}
+	}
+}
+
+// PostRich implements the plugin.RichReplier interface.
+func (m *appCommandMessage) PostRich(msg *rich.Message) {
+	data := renderMessage(msg)
+	m.respond(context.Background(), &discord.WebhookParams{
+		Content:    data.Content,
+		Embeds:     data.Embeds,
+		Components: data.Components,
+	})
+}
+
+// PostContext implements the plugin.ContextReplier interface.
+// Long texts are split into the response and followup messages, and the reference
+// to the first message is returned.
+func (m *appCommandMessage) PostContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	var first plugin.MessageRef
+	for i, chunk := range split.Text(text, maxMessageLength) {
+		ref, err := m.respond(ctx, &discord.WebhookParams{
+			Content: chunk,
+		})
+		if err != nil {
+			return first, err
+		}
+		if i == 0 {
+			first = ref
+		}
+	}
+
+	return first, nil
+}
+
+// MentionContext implements the plugin.ContextReplier interface.
+func (m *appCommandMessage) MentionContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	user := &discord.User{ID: m.userID}
+	return m.PostContext(ctx, user.Mention()+" "+text)
+}
+
+// React implements the plugin.Reactor interface.
+func (m *appCommandMessage) React(ref plugin.MessageRef, emoji string) error {
+	return m.service.React(ref, emoji)
+}
+
+// Mentions implements the plugin.Message interface.
+func (m *appCommandMessage) Mentions() []string {
+	return nil
+}
+
+// MentionTo implements the plugin.Message interface.
+func (m *appCommandMessage) MentionTo(userID string) bool {
+	return false
+}
+
+// PostHelp implements the plugin.Message interface.
+// Help messages are visible only to the user.
+func (m *appCommandMessage) PostHelp(help *plugin.Help) {
+	m.PostEphemeral(m.service.EscapeHelp(help.String()))
+}
+
+// respond sends the message as the response to the command, or a followup message.
+func (m *appCommandMessage) respond(ctx context.Context, params *discord.WebhookParams) (plugin.MessageRef, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	session := m.service.session
+
+	var (
+		msg *discord.Message
+		err error
+	)
+	switch {
+	case !m.responded && params.Flags&discord.MessageFlagsEphemeral == 0:
+		msg, err = session.InteractionResponseEdit(m.interaction, &discord.WebhookEdit{
+			Content:    &params.Content,
+			Embeds:     &params.Embeds,
+			Components: &params.Components,
+		}, discord.WithContext(ctx))
+	case !m.responded:
+		// the deferred response cannot be changed to ephemeral
+		if err := session.InteractionResponseDelete(m.interaction, discord.WithContext(ctx)); err != nil {
+			m.service.l.Error("Failed to delete the response", slog.Any("err", err))
+		}
+		m.responded = true
+		fallthrough
+	default:
+		msg, err = session.FollowupMessageCreate(m.interaction, true, params, discord.WithContext(ctx))
+	}
+	if err != nil {
+		m.service.l.Error("Failed to respond to the command", slog.String("channel_id", m.ChannelID()), slog.Any("err", err))
+		return plugin.MessageRef{}, fmt.Errorf("failed to respond to the command: %w", err)
+	}
+	m.responded = true
+
+	return plugin.MessageRef{
+		ChannelID: msg.ChannelID,
+		MessageID: msg.ID,
+	}, nil
+}
+
+// finish deletes the deferred response if no message has been posted in reply to the command.
+// It is called when the bot has finished handling the command.
+func (m *appCommandMessage) finish() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if m.responded {
+		return
+	}
+	m.responded = true
+
+	if err := m.service.session.InteractionResponseDelete(m.interaction); err != nil {
+		m.service.l.Error("Failed to delete the response", slog.Any("err", err))
+	}
+}
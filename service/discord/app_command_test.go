@@ -0,0 +1,188 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	discord "github.com/bwmarrin/discordgo"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/command"
+)
+
+// textMessage is a plugin.Message that only has the text.
+type textMessage struct {
+	plugin.Message
+	text string
+}
+
+func (m *textMessage) Text() string {
+	return m.text
+}
+
+// echo returns a command.HandlerFunc that returns the flags and the arguments of the names.
+func echo(names ...string) command.HandlerFunc {
+	return func(ctx context.Context, req *command.Request) (string, error) {
+		var s string
+		for _, name := range names {
+			v := req.Flag(name)
+			if v == "" {
+				v = req.String(name)
+			}
+			s += fmt.Sprintf("[%s=%s]", name, v)
+		}
+		return s, nil
+	}
+}
+
+var appCommandRouters = []*command.Router{
+	command.New("cron",
+		&command.Command{
+			Name:        "add",
+			Description: "Add a new schedule.",
+			Flags:       []*command.Flag{{Name: "thread", Value: true}},
+			Args: []*command.Arg{
+				{Name: "name"},
+				{Name: "schedule", Arity: 5},
+				{Name: "command", Rest: true},
+			},
+			Handler: echo("thread", "name", "schedule", "command"),
+		},
+		&command.Command{
+			Name:        "remove",
+			Description: "Remove a schedule.",
+			Args:        []*command.Arg{{Name: "name"}},
+			Handler:     echo("name"),
+		},
+	),
+	command.New("loc",
+		&command.Command{
+			Name:        "add",
+			Description: "Add a new location.",
+			Args: []*command.Arg{
+				{Name: "name"},
+				{Name: "latitude", Type: command.FloatArg},
+				{Name: "longitude", Type: command.FloatArg},
+			},
+			Handler: echo("name", "latitude", "longitude"),
+		},
+	),
+	command.New("note",
+		&command.Command{
+			Name:        "add",
+			Description: "Add a new note.",
+			Flags:       []*command.Flag{{Name: "pin"}},
+			Args: []*command.Arg{
+				{Name: "title"},
+				{Name: "body", Rest: true, Optional: true},
+			},
+			Handler: echo("pin", "title", "body"),
+		},
+	),
+}
+
+func stringOption(name, value string) *discord.ApplicationCommandInteractionDataOption {
+	return &discord.ApplicationCommandInteractionDataOption{
+		Type:  discord.ApplicationCommandOptionString,
+		Name:  name,
+		Value: value,
+	}
+}
+
+func boolOption(name string, value bool) *discord.ApplicationCommandInteractionDataOption {
+	return &discord.ApplicationCommandInteractionDataOption{
+		Type:  discord.ApplicationCommandOptionBoolean,
+		Name:  name,
+		Value: value,
+	}
+}
+
+func subcommandOption(name string, options ...*discord.ApplicationCommandInteractionDataOption) []*discord.ApplicationCommandInteractionDataOption {
+	return []*discord.ApplicationCommandInteractionDataOption{{
+		Type:    discord.ApplicationCommandOptionSubCommand,
+		Name:    name,
+		Options: options,
+	}}
+}
+
+// commandSpecTextTests are keyed by the plugin commands, and cover all the specs of appCommandRouters.
+var commandSpecTextTests = map[string]struct {
+	options []*discord.ApplicationCommandInteractionDataOption
+	want    string
+}{
+	"cron add": {
+		options: subcommandOption("add",
+			stringOption("name", "hourly job"),
+			stringOption("schedule", "0 * * * *"),
+			stringOption("command", `echo "hello, world"`),
+			stringOption("thread", "T 1"),
+		),
+		want: `[thread=T 1][name=hourly job][schedule=0 * * * *][command=echo "hello, world"]`,
+	},
+	"cron remove": {
+		options: subcommandOption("remove", stringOption("name", "hourly job")),
+		want:    "[name=hourly job]",
+	},
+	"loc add": {
+		options: subcommandOption("add",
+			stringOption("name", "New York"),
+			stringOption("latitude", "40.7"),
+			stringOption("longitude", "-74"),
+		),
+		want: "[name=New York][latitude=40.7][longitude=-74]",
+	},
+	"note add": {
+		options: subcommandOption("add",
+			boolOption("pin", true),
+			stringOption("title", "shopping list"),
+			stringOption("body", "milk, eggs"),
+		),
+		want: "[pin=true][title=shopping list][body=milk, eggs]",
+	},
+}
+
+func Test_commandSpec_text(t *testing.T) {
+	var helps []*plugin.Help
+	routers := make(map[string]*command.Router)
+	for _, r := range appCommandRouters {
+		helps = append(helps, &plugin.Help{Name: r.Name(), Commands: r.HelpCommands()})
+		routers[r.Name()] = r
+	}
+
+	specs := commandSpecs(helps)
+	for _, spec := range specs {
+		for _, sub := range spec.subcommands {
+			name := spec.word + " " + sub.word
+			if _, ok := commandSpecTextTests[name]; !ok {
+				t.Errorf("no test of %q", name)
+			}
+		}
+	}
+
+	for name, tt := range commandSpecTextTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			word, _, _ := strings.Cut(name, " ")
+			var spec *commandSpec
+			for _, s := range specs {
+				if s.word == word {
+					spec = s
+					break
+				}
+			}
+			if spec == nil {
+				t.Fatalf("spec of %q is not generated", name)
+			}
+
+			text := spec.text(tt.options)
+			got, err := routers[spec.word].Execute(context.Background(), &textMessage{text: text})
+			if err != nil {
+				t.Fatalf("Router.Execute(%q) => error %v", text, err)
+			}
+			if got != tt.want {
+				t.Errorf("Router.Execute(%q) => %q, want %q", text, got, tt.want)
+			}
+		})
+	}
+}
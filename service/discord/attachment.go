@@ -0,0 +1,52 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	discord "github.com/bwmarrin/discordgo"
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+type attachment struct {
+	service    *discordService
+	attachment *discord.MessageAttachment
+}
+
+var _ plugin.Attachment = (*attachment)(nil)
+
+// Name implements the plugin.Attachment interface.
+func (a *attachment) Name() string {
+	return a.attachment.Filename
+}
+
+// MIMEType implements the plugin.Attachment interface.
+func (a *attachment) MIMEType() string {
+	return a.attachment.ContentType
+}
+
+// Size implements the plugin.Attachment interface.
+func (a *attachment) Size() int64 {
+	return int64(a.attachment.Size)
+}
+
+// Open implements the plugin.Attachment interface.
+func (a *attachment) Open(ctx context.Context) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.attachment.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a request: %w", err)
+	}
+
+	res, err := a.service.session.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the attachment: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("failed to download the attachment: %s", res.Status)
+	}
+
+	return res.Body, nil
+}
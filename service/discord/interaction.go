@@ -0,0 +1,75 @@
+package discord
+
+import (
+	"fmt"
+	"log/slog"
+
+	discord "github.com/bwmarrin/discordgo"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+	"github.com/kechako/gopher-bot/v2/service/internal/split"
+)
+
+type interaction struct {
+	service     *discordService
+	interaction *discord.Interaction
+	userID      string
+	actionID    string
+	value       string
+}
+
+var _ plugin.Interaction = (*interaction)(nil)
+
+// UserID implements the plugin.Interaction interface.
+func (i *interaction) UserID() string {
+	return i.userID
+}
+
+// ActionID implements the plugin.Interaction interface.
+func (i *interaction) ActionID() string {
+	return i.actionID
+}
+
+// Value implements the plugin.Interaction interface.
+func (i *interaction) Value() string {
+	return i.value
+}
+
+// Target implements the plugin.Interaction interface.
+func (i *interaction) Target() plugin.MessageRef {
+	return plugin.MessageRef{
+		ChannelID: i.interaction.ChannelID,
+		MessageID: i.interaction.Message.ID,
+	}
+}
+
+// Update implements the plugin.Interaction interface.
+// The message is edited with the response to the interaction, that has been deferred.
+func (i *interaction) Update(msg *rich.Message) error {
+	data := renderMessage(msg)
+	_, err := i.service.session.InteractionResponseEdit(i.interaction, &discord.WebhookEdit{
+		Content:    &data.Content,
+		Embeds:     &data.Embeds,
+		Components: &data.Components,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the message: %w", err)
+	}
+
+	return nil
+}
+
+// PostEphemeral implements the plugin.Interaction interface.
+// Unlike plugin.Message, the message is an ephemeral followup message of the interaction.
+func (i *interaction) PostEphemeral(text string) {
+	for _, chunk := range split.Text(text, maxMessageLength) {
+		_, err := i.service.session.FollowupMessageCreate(i.interaction, false, &discord.WebhookParams{
+			Content: chunk,
+			Flags:   discord.MessageFlagsEphemeral,
+		})
+		if err != nil {
+			i.service.l.Error("Failed to post an ephemeral message", slog.String("user_id", i.userID), slog.Any("err", err))
+			return
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package discord
 
 import (
+	"context"
+	"log/slog"
+
 	discord "github.com/bwmarrin/discordgo"
 	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
 )
 
 type message struct {
@@ -10,7 +14,16 @@ type message struct {
 	msg     *discord.Message
 }
 
-var _ plugin.Message = (*message)(nil)
+var (
+	_ plugin.Message            = (*message)(nil)
+	_ plugin.ContextReplier     = (*message)(nil)
+	_ plugin.Reactor            = (*message)(nil)
+	_ plugin.ThreadReplier      = (*message)(nil)
+	_ plugin.DirectChecker      = (*message)(nil)
+	_ plugin.EphemeralReplier   = (*message)(nil)
+	_ plugin.RichReplier        = (*message)(nil)
+	_ plugin.AttachmentProvider = (*message)(nil)
+)
 
 // newMessage returns a new *message as plugin.Message.
 func newMessage(service *discordService, msg *discord.Message) plugin.Message {
@@ -30,11 +43,48 @@ func (m *message) UserID() string {
 	return m.msg.Author.ID
 }
 
+// IsDirect implements the plugin.DirectChecker interface.
+func (m *message) IsDirect() bool {
+	return m.msg.GuildID == ""
+}
+
+// ThreadID implements the plugin.ThreadReplier interface.
+// On Discord, threads are channels, so it is the same as ChannelID if the message is in a thread.
+func (m *message) ThreadID() string {
+	ch, err := m.service.channel(m.ChannelID())
+	if err != nil {
+		m.service.l.Error("Failed to get channel info", slog.String("channel_id", m.ChannelID()), slog.Any("err", err))
+		return ""
+	}
+	if !ch.IsThread() {
+		return ""
+	}
+
+	return ch.ID
+}
+
 // Text implements the plugin.Message interface.
 func (m *message) Text() string {
 	return m.msg.Content
 }
 
+// Attachments implements the plugin.AttachmentProvider interface.
+func (m *message) Attachments() []plugin.Attachment {
+	if len(m.msg.Attachments) == 0 {
+		return nil
+	}
+
+	attachments := make([]plugin.Attachment, len(m.msg.Attachments))
+	for i, a := range m.msg.Attachments {
+		attachments[i] = &attachment{
+			service:    m.service,
+			attachment: a,
+		}
+	}
+
+	return attachments
+}
+
 // Post implements the plugin.Message interface.
 func (m *message) Post(text string) {
 	m.service.Post(m.ChannelID(), text)
@@ -45,6 +95,59 @@ func (m *message) Mention(text string) {
 	m.service.Mention(m.ChannelID(), m.UserID(), text)
 }
 
+// ReplyInThread implements the plugin.ThreadReplier interface.
+func (m *message) ReplyInThread(text string) {
+	if threadID := m.ThreadID(); threadID != "" {
+		m.service.Post(threadID, text)
+		return
+	}
+
+	thread, err := m.service.startThread(m.msg)
+	if err != nil {
+		m.service.l.Error("Failed to start a thread", slog.String("channel_id", m.ChannelID()), slog.Any("err", err))
+		m.Post(text)
+		return
+	}
+
+	m.service.Post(thread.ID, text)
+}
+
+// PostToChannel implements the plugin.ThreadReplier interface.
+// If the message is in a thread, the message is posted to the parent channel of the thread.
+func (m *message) PostToChannel(text string) {
+	channelID := m.ChannelID()
+	if ch, err := m.service.channel(channelID); err == nil && ch.IsThread() {
+		channelID = ch.ParentID
+	}
+
+	m.service.Post(channelID, text)
+}
+
+// PostEphemeral implements the plugin.EphemeralReplier interface.
+func (m *message) PostEphemeral(text string) {
+	m.service.PostEphemeral(m.ChannelID(), m.ThreadID(), m.UserID(), text)
+}
+
+// PostRich implements the plugin.RichReplier interface.
+func (m *message) PostRich(msg *rich.Message) {
+	m.service.PostRichToThread(m.ChannelID(), m.ThreadID(), msg)
+}
+
+// PostContext implements the plugin.ContextReplier interface.
+func (m *message) PostContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	return m.service.PostContext(ctx, m.ChannelID(), text)
+}
+
+// MentionContext implements the plugin.ContextReplier interface.
+func (m *message) MentionContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	return m.service.MentionContext(ctx, m.ChannelID(), m.UserID(), text)
+}
+
+// React implements the plugin.Reactor interface.
+func (m *message) React(ref plugin.MessageRef, emoji string) error {
+	return m.service.React(ref, emoji)
+}
+
 // Mentions implements the plugin.Message interface.
 func (m *message) Mentions() []string {
 	mentions := make([]string, 0, len(m.msg.Mentions))
@@ -68,8 +171,13 @@ func (m *message) MentionTo(userID string) bool {
 }
 
 // PostHelp implements the plugin.Message interface.
+// Help messages are posted ephemerally if the service is set to do so.
 func (m *message) PostHelp(help *plugin.Help) {
 	msg := m.service.EscapeHelp(help.String())
 
+	if m.service.ephemeralHelp {
+		m.PostEphemeral(msg)
+		return
+	}
 	m.Post(msg)
 }
@@ -0,0 +1,30 @@
+package discord
+
+import "github.com/kechako/gopher-bot/v2/plugin"
+
+type reaction struct {
+	userID string
+	emoji  string
+	target plugin.MessageRef
+	added  bool
+}
+
+// UserID implements the plugin.Reaction interface.
+func (r *reaction) UserID() string {
+	return r.userID
+}
+
+// Emoji implements the plugin.Reaction interface.
+func (r *reaction) Emoji() string {
+	return r.emoji
+}
+
+// Target implements the plugin.Reaction interface.
+func (r *reaction) Target() plugin.MessageRef {
+	return r.target
+}
+
+// Added implements the plugin.Reaction interface.
+func (r *reaction) Added() bool {
+	return r.added
+}
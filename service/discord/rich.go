@@ -0,0 +1,184 @@
+package discord
+
+import (
+	"strings"
+
+	discord "github.com/bwmarrin/discordgo"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+)
+
+const (
+	// maxEmbedFields is the maximum number of fields in an embed.
+	maxEmbedFields = 25
+	// maxEmbedDescription is the maximum length of the description of an embed.
+	maxEmbedDescription = 4096
+	// maxRowButtons is the maximum number of buttons in an action row.
+	maxRowButtons = 5
+	// maxActionRows is the maximum number of action rows in a message.
+	maxActionRows = 5
+)
+
+// customIDSeparator separates the action ID and the value in custom IDs of buttons,
+// because Discord buttons do not have values.
+const customIDSeparator = "|"
+
+// parseCustomID returns the action ID and the value of the custom ID of a button.
+func parseCustomID(customID string) (actionID, value string) {
+	actionID, value, _ = strings.Cut(customID, customIDSeparator)
+	return actionID, value
+}
+
+// divider is the text of divider blocks, because Discord does not have dividers.
+const divider = "──────────"
+
+// renderMessage renders the rich message to an embed and components.
+// Things that embeds cannot contain are rendered to the description.
+func renderMessage(msg *rich.Message) *discord.MessageSend {
+	if len(msg.Blocks) == 0 {
+		return &discord.MessageSend{
+			Content: msg.Text,
+		}
+	}
+
+	var (
+		embed       discord.MessageEmbed
+		description []string
+		footer      []string
+		rows        []discord.MessageComponent
+	)
+	for i, b := range msg.Blocks {
+		switch b.Type {
+		case rich.SectionBlock:
+			text := b.Text
+			if b.Title != "" {
+				if i == 0 {
+					embed.Title = b.Title
+				} else {
+					text = "**" + b.Title + "**\n" + text
+				}
+			}
+			if text != "" {
+				description = append(description, text)
+			}
+		case rich.FieldsBlock:
+			for _, f := range b.Fields {
+				if len(embed.Fields) < maxEmbedFields {
+					embed.Fields = append(embed.Fields, &discord.MessageEmbedField{
+						Name:   f.Name,
+						Value:  f.Value,
+						Inline: true,
+					})
+				} else {
+					description = append(description, "**"+f.Name+"**: "+f.Value)
+				}
+			}
+		case rich.CodeBlock:
+			code := strings.TrimSuffix(b.Text, "\n")
+			description = append(description, "```"+b.Language+"\n"+code+"\n```")
+		case rich.ImageBlock:
+			if embed.Image == nil {
+				embed.Image = &discord.MessageEmbedImage{URL: b.URL}
+			} else {
+				description = append(description, b.URL)
+			}
+		case rich.ContextBlock:
+			footer = append(footer, b.Text)
+		case rich.ActionsBlock:
+			buttons := make([]discord.MessageComponent, 0, len(b.Buttons))
+			for _, btn := range b.Buttons {
+				buttons = append(buttons, renderButton(btn))
+			}
+			rows = append(rows, actionRows(buttons)...)
+		case rich.DividerBlock:
+			description = append(description, divider)
+		case rich.SelectBlock:
+			// a select menu takes up a whole row
+			rows = append(rows, discord.ActionsRow{
+				Components: []discord.MessageComponent{renderSelect(b)},
+			})
+		}
+	}
+
+	embed.Description = truncate(strings.Join(description, "\n"), maxEmbedDescription)
+	if len(footer) > 0 {
+		embed.Footer = &discord.MessageEmbedFooter{Text: strings.Join(footer, "\n")}
+	}
+
+	if len(rows) > maxActionRows {
+		// rows that do not fit in the message are dropped
+		rows = rows[:maxActionRows]
+	}
+
+	return &discord.MessageSend{
+		Embeds:     []*discord.MessageEmbed{&embed},
+		Components: rows,
+	}
+}
+
+func renderButton(btn *rich.Button) discord.Button {
+	if btn.URL != "" {
+		return discord.Button{
+			Label: btn.Label,
+			Style: discord.LinkButton,
+			URL:   btn.URL,
+		}
+	}
+
+	b := discord.Button{
+		Label:    btn.Label,
+		Style:    discord.SecondaryButton,
+		CustomID: btn.ActionID,
+	}
+	if btn.Value != "" {
+		b.CustomID += customIDSeparator + btn.Value
+	}
+
+	switch btn.Style {
+	case rich.PrimaryButton:
+		b.Style = discord.PrimaryButton
+	case rich.DangerButton:
+		b.Style = discord.DangerButton
+	}
+
+	return b
+}
+
+func renderSelect(b *rich.Block) discord.SelectMenu {
+	options := make([]discord.SelectMenuOption, 0, len(b.Options))
+	for _, o := range b.Options {
+		options = append(options, discord.SelectMenuOption{
+			Label: o.Label,
+			Value: o.Value,
+		})
+	}
+
+	return discord.SelectMenu{
+		MenuType:    discord.StringSelectMenu,
+		CustomID:    b.ActionID,
+		Placeholder: b.Text,
+		Options:     options,
+	}
+}
+
+// actionRows lays out the buttons in action rows.
+func actionRows(components []discord.MessageComponent) []discord.MessageComponent {
+	var rows []discord.MessageComponent
+	for i := 0; i < len(components); i += maxRowButtons {
+		end := min(i+maxRowButtons, len(components))
+		rows = append(rows, discord.ActionsRow{
+			Components: components[i:end],
+		})
+	}
+
+	return rows
+}
+
+// truncate truncates s to n runes.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n-1]) + "…"
+}
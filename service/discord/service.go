@@ -6,17 +6,37 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	discord "github.com/bwmarrin/discordgo"
 	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
 	"github.com/kechako/gopher-bot/v2/service"
+	"github.com/kechako/gopher-bot/v2/service/internal/cache"
+	"github.com/kechako/gopher-bot/v2/service/internal/outbox"
+	"github.com/kechako/gopher-bot/v2/service/internal/split"
 )
 
 type Config struct {
 	Logger *slog.Logger
+	// CommandGuildID is the ID of the guild that application commands of plugins are registered to.
+	// Commands are registered globally if it is empty.
+	// Guild commands are available immediately, while global commands may take a while.
+	CommandGuildID string
+	// MaxSplitMessages is the maximum number of messages that a long text is split into.
+	// Texts that need more messages are uploaded as a text file instead.
+	// Texts are always split if it is zero.
+	MaxSplitMessages int
+	// UserCacheTTL is the duration to cache users, guild members and roles.
+	// The default is 10 minutes.
+	UserCacheTTL time.Duration
 }
 
 func (cfg *Config) logger() *slog.Logger {
@@ -30,13 +50,43 @@ func (cfg *Config) logger() *slog.Logger {
 	return slog.New(slog.NewTextHandler(os.Stdout, nil))
 }
 
+// defaultUserCacheTTL is the default duration to cache users.
+const defaultUserCacheTTL = 10 * time.Minute
+
 // discordService represents a service for Discord.
 type discordService struct {
 	session *discord.Session
+	outbox  *outbox.Outbox
 	ch      chan *service.Event
 	l       *slog.Logger
+
+	commandGuildID string
+	commands       map[string]*commandSpec
+	commandsMux    sync.Mutex
+
+	maxSplitMessages int
+	ephemeralHelp    bool
+
+	users   *cache.Cache[string, *discordUser]
+	members *cache.Cache[string, *discord.Member]
+	roles   *cache.Cache[string, []*discord.Role]
+
+	done <-chan struct{}
+	exit context.CancelFunc
 }
 
+var (
+	_ service.Service         = (*discordService)(nil)
+	_ service.ContextPoster   = (*discordService)(nil)
+	_ service.Editor          = (*discordService)(nil)
+	_ service.Reactor         = (*discordService)(nil)
+	_ service.ThreadPoster    = (*discordService)(nil)
+	_ service.DirectMessenger = (*discordService)(nil)
+	_ service.RichPoster      = (*discordService)(nil)
+	_ service.Uploader        = (*discordService)(nil)
+	_ service.EphemeralHelper = (*discordService)(nil)
+)
+
 // New returns a new Discord service as service.Service.
 func New(token string, cfg *Config) (service.Service, error) {
 	if token == "" {
@@ -52,6 +102,22 @@ func New(token string, cfg *Config) (service.Service, error) {
 		session: session,
 		l:       cfg.logger(),
 	}
+	ttl := defaultUserCacheTTL
+	if cfg != nil {
+		s.commandGuildID = cfg.CommandGuildID
+		s.maxSplitMessages = cfg.MaxSplitMessages
+		if cfg.UserCacheTTL > 0 {
+			ttl = cfg.UserCacheTTL
+		}
+	}
+	s.users = cache.New[string, *discordUser](ttl)
+	s.members = cache.New[string, *discord.Member](ttl)
+	s.roles = cache.New[string, []*discord.Role](ttl)
+	s.outbox = outbox.New(s.deliver, &outbox.Config{
+		Logger:    s.l,
+		Name:      "discord",
+		OnFailure: s.handleDeliveryFailure,
+	})
 	s.addHandlers()
 
 	return s, nil
@@ -63,7 +129,15 @@ func (s *discordService) Start(ctx context.Context) (<-chan *service.Event, erro
 
 	s.ch = make(chan *service.Event)
 
+	ctx, cancel := context.WithCancel(ctx)
+	s.done = ctx.Done()
+	s.exit = cancel
+
+	s.outbox.Start(ctx)
+
 	if err := s.session.Open(); err != nil {
+		cancel()
+		s.outbox.Close()
 		close(s.ch)
 		return nil, err
 	}
@@ -71,9 +145,20 @@ func (s *discordService) Start(ctx context.Context) (<-chan *service.Event, erro
 	return s.ch, nil
 }
 
+// send sends the event to the bot, unless the service is closed.
+func (s *discordService) send(event *service.Event) {
+	select {
+	case <-s.done:
+	case s.ch <- event:
+	}
+}
+
 // Start implements the service.Service interface.
 func (s *discordService) Close() error {
-	return s.session.Close()
+	s.exit()
+	err := s.session.Close()
+	s.outbox.Close()
+	return err
 }
 
 // UserID implements the service.Service interface.
@@ -83,10 +168,190 @@ func (s *discordService) UserID() string {
 
 // Post implements the service.Service interface.
 func (s *discordService) Post(channelID, text string) {
-	_, err := s.session.ChannelMessageSend(channelID, text)
+	s.post(context.Background(), channelID, text)
+}
+
+// PostToThread implements the service.ThreadPoster interface.
+// On Discord, threads are channels, so the message is posted to the thread channel of threadID.
+func (s *discordService) PostToThread(channelID, threadID, text string) {
+	s.Post(threadChannel(channelID, threadID), text)
+}
+
+// PostToThreadContext posts a new message to the thread of the channel, and waits until it is delivered.
+func (s *discordService) PostToThreadContext(ctx context.Context, channelID, threadID, text string) (plugin.MessageRef, error) {
+	return s.PostContext(ctx, threadChannel(channelID, threadID), text)
+}
+
+// threadChannel returns the ID of the channel to post messages to the thread.
+func threadChannel(channelID, threadID string) string {
+	if threadID != "" {
+		return threadID
+	}
+	return channelID
+}
+
+// DirectMessage implements the service.DirectMessenger interface.
+func (s *discordService) DirectMessage(userID, text string) error {
+	ch, err := s.session.UserChannelCreate(userID)
+	if err != nil {
+		return fmt.Errorf("failed to create the direct message channel: %w", err)
+	}
+
+	s.Post(ch.ID, text)
+	return nil
+}
+
+// PostEphemeral posts a new message that is visible only to the user.
+// Discord supports ephemeral messages only in replies to interactions,
+// so the message is sent as a direct message.
+func (s *discordService) PostEphemeral(channelID, threadID, userID, text string) {
+	if err := s.DirectMessage(userID, text); err != nil {
+		s.l.Error("Failed to post an ephemeral message", slog.String("user_id", userID), slog.Any("err", err))
+	}
+}
+
+// isDirectChannel returns whether the channel is a direct message channel.
+func (s *discordService) isDirectChannel(channelID string) bool {
+	ch, err := s.channel(channelID)
 	if err != nil {
-		s.l.Error("Failed to post message", slog.String("channel_id", channelID), slog.Any("err", err))
+		s.l.Error("Failed to get channel info", slog.String("channel_id", channelID), slog.Any("err", err))
+		return false
 	}
+
+	return ch.Type == discord.ChannelTypeDM
+}
+
+// PostContext implements the service.ContextPoster interface.
+func (s *discordService) PostContext(ctx context.Context, channelID, text string) (plugin.MessageRef, error) {
+	return s.post(ctx, channelID, text).Wait(ctx)
+}
+
+// post pushes the text to the outbox, split into messages if it is too long.
+// The returned *outbox.Delivery reports the result of the first message.
+func (s *discordService) post(ctx context.Context, channelID, text string) *outbox.Delivery {
+	var delivery *outbox.Delivery
+	for _, chunk := range s.splitText(text) {
+		d := s.outbox.Push(ctx, &outbox.Message{
+			ChannelID: channelID,
+			Text:      chunk,
+		})
+		if delivery == nil {
+			delivery = d
+		}
+	}
+
+	return delivery
+}
+
+// maxMessageLength is the maximum length of the content of a message.
+const maxMessageLength = 2000
+
+// splitText splits the text to fit in messages.
+// Returns the text as it is if it needs more than maxSplitMessages messages,
+// so that it is uploaded as a file when it is delivered.
+func (s *discordService) splitText(text string) []string {
+	chunks := split.Text(text, maxMessageLength)
+	if s.maxSplitMessages > 0 && len(chunks) > s.maxSplitMessages {
+		return []string{text}
+	}
+
+	return chunks
+}
+
+// PostRich implements the service.RichPoster interface.
+func (s *discordService) PostRich(channelID string, msg *rich.Message) {
+	s.postRich(context.Background(), channelID, msg)
+}
+
+// PostRichContext implements the service.RichPoster interface.
+func (s *discordService) PostRichContext(ctx context.Context, channelID string, msg *rich.Message) (plugin.MessageRef, error) {
+	return s.postRich(ctx, channelID, msg).Wait(ctx)
+}
+
+// PostRichToThread posts a new rich message to the thread of the channel.
+func (s *discordService) PostRichToThread(channelID, threadID string, msg *rich.Message) {
+	s.postRich(context.Background(), threadChannel(channelID, threadID), msg)
+}
+
+func (s *discordService) postRich(ctx context.Context, channelID string, msg *rich.Message) *outbox.Delivery {
+	return s.outbox.Push(ctx, &outbox.Message{
+		ChannelID: channelID,
+		Text:      msg.PlainText(),
+		Rich:      msg,
+	})
+}
+
+// deliver sends the message of the outbox to Discord.
+func (s *discordService) deliver(ctx context.Context, msg *outbox.Message) (plugin.MessageRef, error) {
+	data := &discord.MessageSend{
+		Content: msg.Text,
+	}
+	switch {
+	case msg.Rich != nil:
+		data = renderMessage(msg.Rich)
+	case utf8.RuneCountInString(msg.Text) > maxMessageLength:
+		// the text is too long to be posted, so it is uploaded as a file
+		data = &discord.MessageSend{
+			Files: []*discord.File{{
+				Name:        "message.txt",
+				ContentType: "text/plain",
+				Reader:      strings.NewReader(split.Unfence(msg.Text)),
+			}},
+		}
+	}
+
+	m, err := s.session.ChannelMessageSendComplex(msg.ChannelID, data, discord.WithContext(ctx))
+	if err != nil {
+		return plugin.MessageRef{}, classifyError(err)
+	}
+
+	return plugin.MessageRef{
+		ChannelID: m.ChannelID,
+		MessageID: m.ID,
+	}, nil
+}
+
+// classifyError converts err of the Discord API for the outbox.
+// Rate limits of the buckets are handled by discordgo, unless
+// Session.ShouldRetryOnRateLimit is false.
+func classifyError(err error) error {
+	var (
+		rateLimit *discord.RateLimitError
+		restErr   *discord.RESTError
+	)
+	switch {
+	case errors.As(err, &rateLimit):
+		return &outbox.RateLimitError{RetryAfter: rateLimit.RetryAfter, Err: err}
+	case errors.As(err, &restErr) && restErr.Response != nil:
+		code := restErr.Response.StatusCode
+		if code >= 400 && code < 500 && code != http.StatusTooManyRequests {
+			return outbox.Permanent(err)
+		}
+	}
+
+	return err
+}
+
+// handleDeliveryFailure handles the message that the outbox has given up delivering.
+func (s *discordService) handleDeliveryFailure(ctx context.Context, msg *outbox.Message, err error) {
+	s.send(&service.Event{
+		Type: service.DeliveryFailedEvent,
+		Data: &plugin.DeliveryFailure{
+			ChannelID: msg.ChannelID,
+			Text:      msg.Text,
+			Err:       err,
+		},
+	})
+}
+
+// Upload implements the service.Uploader interface.
+func (s *discordService) Upload(channelID, name string, r io.Reader, comment string) error {
+	_, err := s.session.ChannelFileSendWithMessage(channelID, comment, name, r)
+	if err != nil {
+		return fmt.Errorf("failed to upload the file: %w", err)
+	}
+
+	return nil
 }
 
 // Mention implements the service.Service interface.
@@ -94,28 +359,96 @@ func (s *discordService) Mention(channelID, userID, text string) {
 	user, err := s.session.User(userID)
 	if err != nil {
 		s.l.Error("Failed to get user info", slog.String("user_id", userID), slog.Any("err", err))
+		return
 	}
 
-	text = user.Mention() + text
-	_, err = s.session.ChannelMessageSend(channelID, text)
+	s.Post(channelID, user.Mention()+text)
+}
+
+// MentionContext implements the service.ContextPoster interface.
+func (s *discordService) MentionContext(ctx context.Context, channelID, userID, text string) (plugin.MessageRef, error) {
+	user, err := s.session.User(userID, discord.WithContext(ctx))
 	if err != nil {
-		s.l.Error("Failed to post mention message", slog.String("channel_id", channelID), slog.Any("err", err))
+		return plugin.MessageRef{}, fmt.Errorf("failed to get user info: %w", err)
 	}
+
+	return s.PostContext(ctx, channelID, user.Mention()+text)
+}
+
+// MentionToThread posts a new message that mentions to the user to the thread of the channel.
+func (s *discordService) MentionToThread(channelID, threadID, userID, text string) {
+	s.Mention(threadChannel(channelID, threadID), userID, text)
+}
+
+// MentionToThreadContext posts a new message that mentions to the user to the thread of the channel,
+// and waits until it is delivered.
+func (s *discordService) MentionToThreadContext(ctx context.Context, channelID, threadID, userID, text string) (plugin.MessageRef, error) {
+	return s.MentionContext(ctx, threadChannel(channelID, threadID), userID, text)
+}
+
+// Edit implements the service.Editor interface.
+func (s *discordService) Edit(ref plugin.MessageRef, text string) error {
+	_, err := s.session.ChannelMessageEdit(ref.ChannelID, ref.MessageID, text)
+	if err != nil {
+		return fmt.Errorf("failed to edit the message: %w", err)
+	}
+
+	return nil
+}
+
+// Delete implements the service.Editor interface.
+func (s *discordService) Delete(ref plugin.MessageRef) error {
+	err := s.session.ChannelMessageDelete(ref.ChannelID, ref.MessageID)
+	if err != nil {
+		return fmt.Errorf("failed to delete the message: %w", err)
+	}
+
+	return nil
+}
+
+// React implements the service.Reactor interface.
+// emoji is the Unicode emoji, or name:id for custom emojis.
+func (s *discordService) React(ref plugin.MessageRef, emoji string) error {
+	err := s.session.MessageReactionAdd(ref.ChannelID, ref.MessageID, emoji)
+	if err != nil {
+		return fmt.Errorf("failed to add the reaction: %w", err)
+	}
+
+	return nil
 }
 
 // ProcessCommmand processes the specified command on the channel.
 func (s *discordService) ProcessCommand(channelID string, command string) {
-	go func() {
-		s.ch <- &service.Event{
-			Type: service.MessageEvent,
-			Data: newCommandMessage(s, channelID, command),
-		}
-	}()
+	go s.ProcessCommandContext(context.Background(), channelID, command)
+}
+
+// ProcessCommandContext processes the specified command on the channel.
+func (s *discordService) ProcessCommandContext(ctx context.Context, channelID string, command string) error {
+	return s.processCommand(ctx, channelID, "", command)
+}
+
+// ProcessThreadCommand processes the specified command on the thread of the channel.
+func (s *discordService) ProcessThreadCommand(channelID, threadID, command string) {
+	go s.processCommand(context.Background(), channelID, threadID, command)
+}
+
+func (s *discordService) processCommand(ctx context.Context, channelID, threadID, command string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.done:
+		return service.ErrClosed
+	case s.ch <- &service.Event{
+		Type: service.MessageEvent,
+		Data: newCommandMessage(s, channelID, threadID, command),
+	}:
+		return nil
+	}
 }
 
 // Channel returns a channel of specified channelID.
 func (s *discordService) Channel(channelID string) plugin.Channel {
-	ch, err := s.session.Channel(channelID)
+	ch, err := s.channel(channelID)
 	if err != nil {
 		s.l.Error("Failed to get channel info", slog.String("channel_id", channelID), slog.Any("err", err))
 		return nil
@@ -127,18 +460,52 @@ func (s *discordService) Channel(channelID string) plugin.Channel {
 	}
 }
 
-// User returns a user of specified userID.
-func (s *discordService) User(userID string) plugin.User {
-	u, err := s.session.User(userID)
+// channel returns the channel from the state cache, or from the API if it is not cached.
+func (s *discordService) channel(channelID string) (*discord.Channel, error) {
+	if ch, err := s.session.State.Channel(channelID); err == nil {
+		return ch, nil
+	}
+
+	return s.session.Channel(channelID)
+}
+
+// threadArchiveDuration is the duration in minutes to archive threads started by the bot.
+const threadArchiveDuration = 24 * 60
+
+// startThread starts a new thread from the message.
+func (s *discordService) startThread(msg *discord.Message) (*discord.Channel, error) {
+	if msg.Thread != nil {
+		return msg.Thread, nil
+	}
+
+	ch, err := s.session.MessageThreadStart(msg.ChannelID, msg.ID, threadName(msg.Content), threadArchiveDuration)
 	if err != nil {
-		s.l.Error("Failed to get user info", slog.String("user_id", userID), slog.Any("err", err))
-		return nil
+		return nil, fmt.Errorf("failed to start a thread: %w", err)
 	}
 
-	return &user{
-		id:   u.ID,
-		name: u.Username,
+	return ch, nil
+}
+
+// maxThreadNameLength is the max length of thread names allowed by Discord.
+const maxThreadNameLength = 100
+
+// threadName returns the name of the thread started from the message of the content.
+func threadName(content string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	if name == "" {
+		return "thread"
 	}
+
+	if r := []rune(name); len(r) > maxThreadNameLength {
+		name = string(r[:maxThreadNameLength])
+	}
+
+	return name
+}
+
+// SetEphemeralHelp implements the service.EphemeralHelper interface.
+func (s *discordService) SetEphemeralHelp(ephemeral bool) {
+	s.ephemeralHelp = ephemeral
 }
 
 // EscapeHelp implements the service.Service interface.
@@ -170,9 +537,53 @@ func (s *discordService) addHandlers() {
 		s.handleReady(event)
 	})
 
+	s.session.AddHandler(func(session *discord.Session, event *discord.Resumed) {
+		s.handleResumed(event)
+	})
+
 	s.session.AddHandler(func(session *discord.Session, event *discord.MessageCreate) {
 		s.handleMessageCreate(event)
 	})
+
+	s.session.AddHandler(func(session *discord.Session, event *discord.MessageUpdate) {
+		s.handleMessageUpdate(event)
+	})
+
+	s.session.AddHandler(func(session *discord.Session, event *discord.MessageDelete) {
+		s.handleMessageDelete(event)
+	})
+
+	s.session.AddHandler(func(session *discord.Session, event *discord.MessageReactionAdd) {
+		s.handleReaction(event.MessageReaction, true)
+	})
+
+	s.session.AddHandler(func(session *discord.Session, event *discord.MessageReactionRemove) {
+		s.handleReaction(event.MessageReaction, false)
+	})
+
+	s.session.AddHandler(func(session *discord.Session, event *discord.InteractionCreate) {
+		s.handleInteractionCreate(event)
+	})
+
+	s.session.AddHandler(func(session *discord.Session, event *discord.GuildMemberUpdate) {
+		s.forgetMember(event.Member)
+	})
+
+	s.session.AddHandler(func(session *discord.Session, event *discord.GuildMemberRemove) {
+		s.forgetMember(event.Member)
+	})
+
+	s.session.AddHandler(func(session *discord.Session, event *discord.GuildRoleCreate) {
+		s.roles.Delete(event.GuildID)
+	})
+
+	s.session.AddHandler(func(session *discord.Session, event *discord.GuildRoleUpdate) {
+		s.roles.Delete(event.GuildID)
+	})
+
+	s.session.AddHandler(func(session *discord.Session, event *discord.GuildRoleDelete) {
+		s.roles.Delete(event.GuildID)
+	})
 }
 
 // handleConnect handles the Connect event.
@@ -181,18 +592,37 @@ func (s *discordService) handleConnect(msg *discord.Connect) {
 }
 
 // handleDisconnect handles the Disconnect event.
+// discordgo.Session reconnects automatically after it.
 func (s *discordService) handleDisconnect(msg *discord.Disconnect) {
 	s.l.Info("Discord session is disconnected")
+
+	s.outbox.SetConnected(false)
+	s.send(&service.Event{
+		Type: service.DisconnectedEvent,
+	})
 }
 
 // handleReady handles the Ready event.
 func (s *discordService) handleReady(msg *discord.Ready) {
 	s.l.Info("Discord session is ready")
 
-	s.ch <- &service.Event{
+	s.outbox.SetConnected(true)
+	s.send(&service.Event{
 		Type: service.ConnectedEvent,
 		Data: newHello(s),
-	}
+	})
+}
+
+// handleResumed handles the Resumed event, which is sent instead of the Ready event
+// when the session is resumed after reconnecting.
+func (s *discordService) handleResumed(msg *discord.Resumed) {
+	s.l.Info("Discord session is resumed")
+
+	s.outbox.SetConnected(true)
+	s.send(&service.Event{
+		Type: service.ConnectedEvent,
+		Data: newHello(s),
+	})
 }
 
 // handleMessageCreate handles the MessageCreate event.
@@ -202,8 +632,193 @@ func (s *discordService) handleMessageCreate(msg *discord.MessageCreate) {
 		return
 	}
 
-	s.ch <- &service.Event{
+	s.send(&service.Event{
 		Type: service.MessageEvent,
 		Data: newMessage(s, msg.Message),
+	})
+}
+
+// handleMessageUpdate handles the MessageUpdate event.
+func (s *discordService) handleMessageUpdate(msg *discord.MessageUpdate) {
+	if msg.Author == nil || msg.Author.ID == s.UserID() {
+		// embeds are updated, or bot message
+		return
+	}
+	if msg.BeforeUpdate != nil && msg.BeforeUpdate.Content == msg.Content {
+		return
+	}
+
+	s.send(&service.Event{
+		Type: service.MessageEditedEvent,
+		Data: newMessage(s, msg.Message),
+	})
+}
+
+// handleMessageDelete handles the MessageDelete event.
+// The author of the message is known only if the message is cached in the state.
+func (s *discordService) handleMessageDelete(msg *discord.MessageDelete) {
+	if before := msg.BeforeDelete; before != nil && before.Author != nil && before.Author.ID == s.UserID() {
+		// bot message
+		return
+	}
+
+	s.send(&service.Event{
+		Type: service.MessageDeletedEvent,
+		Data: &plugin.MessageRef{
+			ChannelID: msg.ChannelID,
+			MessageID: msg.ID,
+		},
+	})
+}
+
+// handleReaction handles the MessageReactionAdd and MessageReactionRemove events.
+func (s *discordService) handleReaction(r *discord.MessageReaction, added bool) {
+	if r.UserID == s.UserID() {
+		// bot reaction
+		return
 	}
+
+	s.send(&service.Event{
+		Type: service.ReactionEvent,
+		Data: &reaction{
+			userID: r.UserID,
+			emoji:  r.Emoji.APIName(),
+			target: plugin.MessageRef{
+				ChannelID: r.ChannelID,
+				MessageID: r.MessageID,
+			},
+			added: added,
+		},
+	})
+}
+
+// handleInteractionCreate handles the InteractionCreate event.
+func (s *discordService) handleInteractionCreate(event *discord.InteractionCreate) {
+	switch event.Type {
+	case discord.InteractionApplicationCommand:
+		s.handleApplicationCommand(event.Interaction)
+	case discord.InteractionMessageComponent:
+		s.handleMessageComponent(event.Interaction)
+	}
+}
+
+// handleApplicationCommand handles the interaction of an application command,
+// as a message of the plugin command posted by the user.
+// The response is deferred at once, because Discord requires a response in 3 seconds.
+func (s *discordService) handleApplicationCommand(i *discord.Interaction) {
+	data := i.ApplicationCommandData()
+
+	s.commandsMux.Lock()
+	spec := s.commands[data.Name]
+	s.commandsMux.Unlock()
+	if spec == nil {
+		// the command is removed, but the client of the user has not updated it yet
+		s.l.Warn("Unknown application command", slog.String("name", data.Name))
+		err := s.session.InteractionRespond(i, &discord.InteractionResponse{
+			Type: discord.InteractionResponseChannelMessageWithSource,
+			Data: &discord.InteractionResponseData{
+				Content: fmt.Sprintf("Unknown command: /%s", data.Name),
+				Flags:   discord.MessageFlagsEphemeral,
+			},
+		})
+		if err != nil {
+			s.l.Error("Failed to respond to the interaction", slog.Any("err", err))
+		}
+		return
+	}
+
+	err := s.session.InteractionRespond(i, &discord.InteractionResponse{
+		Type: discord.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		s.l.Error("Failed to respond to the interaction", slog.Any("err", err))
+		return
+	}
+
+	m := newAppCommandMessage(s, i, spec.text(data.Options))
+	s.send(&service.Event{
+		Type: service.MessageEvent,
+		Data: m,
+		Done: m.finish,
+	})
+}
+
+// handleMessageComponent handles the interaction of a message component.
+// The response is deferred at once, because Discord requires a response in 3 seconds.
+func (s *discordService) handleMessageComponent(i *discord.Interaction) {
+	if i.Message == nil {
+		return
+	}
+
+	err := s.session.InteractionRespond(i, &discord.InteractionResponse{
+		Type: discord.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		s.l.Error("Failed to respond to the interaction", slog.Any("err", err))
+		return
+	}
+
+	data := i.MessageComponentData()
+	actionID, value := parseCustomID(data.CustomID)
+	if len(data.Values) > 0 {
+		value = data.Values[0]
+	}
+
+	s.send(&service.Event{
+		Type: service.InteractionEvent,
+		Data: &interaction{
+			service:     s,
+			interaction: i,
+			userID:      interactionUserID(i),
+			actionID:    actionID,
+			value:       value,
+		},
+	})
+}
+
+// interactionUserID returns the ID of the user of the interaction.
+func interactionUserID(i *discord.Interaction) string {
+	switch {
+	case i.Member != nil && i.Member.User != nil:
+		return i.Member.User.ID
+	case i.User != nil:
+		// interaction in a direct message channel
+		return i.User.ID
+	}
+
+	return ""
+}
+
+// RegisterCommands implements the service.CommandRegistrar interface.
+// Application commands registered before are overwritten, so that stale commands are removed.
+func (s *discordService) RegisterCommands(ctx context.Context, helps []*plugin.Help) error {
+	specs := commandSpecs(helps)
+
+	commands := make([]*discord.ApplicationCommand, 0, len(specs))
+	for _, spec := range specs {
+		commands = append(commands, spec.applicationCommand())
+	}
+
+	appID := s.UserID()
+	if app := s.session.State.Application; app != nil && app.ID != "" {
+		appID = app.ID
+	}
+
+	_, err := s.session.ApplicationCommandBulkOverwrite(appID, s.commandGuildID, commands, discord.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to register application commands: %w", err)
+	}
+
+	registered := make(map[string]*commandSpec, len(specs))
+	for _, spec := range specs {
+		registered[spec.name] = spec
+	}
+
+	s.commandsMux.Lock()
+	s.commands = registered
+	s.commandsMux.Unlock()
+
+	s.l.Info("Application commands are registered", slog.Int("count", len(commands)))
+
+	return nil
 }
@@ -1,8 +1,53 @@
 package discord
 
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	discord "github.com/bwmarrin/discordgo"
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+// avatarSize is the size of avatar images in pixels.
+const avatarSize = "256"
+
+// discordUser is a user of the Discord API with the global name,
+// that discord.User does not have.
+type discordUser struct {
+	discord.User
+	// GlobalName is the display name of the user, that is not unique unlike the username.
+	GlobalName string `json:"global_name"`
+}
+
+// discordMember is a guild member of the Discord API with the global name of the user.
+type discordMember struct {
+	discord.Member
+	User *discordUser `json:"user"`
+}
+
 type user struct {
-	id   string
-	name string
+	id         string
+	name       string
+	globalName string
+	avatarURL  string
+	bot        bool
+}
+
+var (
+	_ plugin.User    = (*user)(nil)
+	_ plugin.Profile = (*user)(nil)
+)
+
+// newUser returns a new *user of u.
+func newUser(u *discordUser) *user {
+	return &user{
+		id:         u.ID,
+		name:       u.Username,
+		globalName: u.GlobalName,
+		avatarURL:  u.AvatarURL(avatarSize),
+		bot:        u.Bot,
+	}
 }
 
 // ID implements the plugin.User interface.
@@ -11,16 +56,241 @@ func (u *user) ID() string {
 }
 
 // Name implements the plugin.User interface.
+// It is the unique username of the user.
 func (u *user) Name() string {
 	return u.name
 }
 
 // FullName implements the plugin.User interface.
+// It is the global display name of the user, or the username if it is not set.
 func (u *user) FullName() string {
-	return u.name
+	if u.globalName == "" {
+		return u.name
+	}
+	return u.globalName
 }
 
 // DisplayName implements the plugin.User interface.
+// It is the same as FullName.
 func (u *user) DisplayName() string {
-	return u.name
+	return u.FullName()
+}
+
+// AvatarURL implements the plugin.Profile interface.
+func (u *user) AvatarURL() string {
+	return u.avatarURL
+}
+
+// IsBot implements the plugin.Profile interface.
+func (u *user) IsBot() bool {
+	return u.bot
+}
+
+type member struct {
+	*user
+	nickname string
+	roles    []string
+}
+
+var (
+	_ plugin.Member  = (*member)(nil)
+	_ plugin.Profile = (*member)(nil)
+)
+
+// DisplayName implements the plugin.User interface.
+// It is the nickname in the guild, or the global display name if it is not set.
+func (m *member) DisplayName() string {
+	if m.nickname == "" {
+		return m.user.DisplayName()
+	}
+	return m.nickname
+}
+
+// Nickname implements the plugin.Member interface.
+func (m *member) Nickname() string {
+	return m.nickname
+}
+
+// Roles implements the plugin.Member interface.
+func (m *member) Roles() []string {
+	if len(m.roles) == 0 {
+		return nil
+	}
+
+	roles := make([]string, len(m.roles))
+	copy(roles, m.roles)
+
+	return roles
+}
+
+// User returns a user of specified userID.
+func (s *discordService) User(userID string) plugin.User {
+	u, err := s.user(userID)
+	if err != nil {
+		s.l.Error("Failed to get user info", slog.String("user_id", userID), slog.Any("err", err))
+		return nil
+	}
+
+	return newUser(u)
+}
+
+// Member returns a member of the guild of the channel.
+func (s *discordService) Member(channelID, userID string) plugin.Member {
+	ch, err := s.channel(channelID)
+	if err != nil {
+		s.l.Error("Failed to get channel info", slog.String("channel_id", channelID), slog.Any("err", err))
+		return nil
+	}
+	if ch.GuildID == "" {
+		return nil
+	}
+
+	m, err := s.member(ch.GuildID, userID)
+	if err != nil {
+		s.l.Error("Failed to get member info", slog.String("guild_id", ch.GuildID), slog.String("user_id", userID), slog.Any("err", err))
+		return nil
+	}
+	u, err := s.user(userID)
+	if err != nil {
+		s.l.Error("Failed to get user info", slog.String("user_id", userID), slog.Any("err", err))
+		return nil
+	}
+
+	mu := newUser(u)
+	if m.User != nil {
+		// the avatar in the guild, or the avatar of the user
+		mu.avatarURL = m.AvatarURL(avatarSize)
+	}
+
+	return &member{
+		user:     mu,
+		nickname: m.Nick,
+		roles:    s.roleNames(ch.GuildID, m.Roles),
+	}
+}
+
+// user returns the user from the cache, or from the API if it is not cached.
+// The state does not have users with global names, so the API is called directly.
+func (s *discordService) user(userID string) (*discordUser, error) {
+	if u, ok := s.users.Get(userID); ok {
+		return u, nil
+	}
+
+	body, err := s.session.RequestWithBucketID("GET", discord.EndpointUser(userID), nil, discord.EndpointUsers)
+	if err != nil {
+		return nil, err
+	}
+
+	var u discordUser
+	if err := json.Unmarshal(body, &u); err != nil {
+		return nil, fmt.Errorf("failed to decode the user: %w", err)
+	}
+	s.users.Set(userID, &u)
+
+	return &u, nil
+}
+
+// member returns the member from the cache, the state, or the API.
+// Members fetched from the API are added to the state.
+// A copy of the member and its user is cached and returned, because the state updates them in place.
+func (s *discordService) member(guildID, userID string) (*discord.Member, error) {
+	key := guildID + "/" + userID
+	if m, ok := s.members.Get(key); ok {
+		return m, nil
+	}
+
+	m, err := s.session.State.Member(guildID, userID)
+	if err != nil {
+		m, err = s.fetchMember(guildID, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.session.State.RLock()
+	member := *m
+	if m.User != nil {
+		user := *m.User
+		member.User = &user
+	}
+	s.session.State.RUnlock()
+	s.members.Set(key, &member)
+
+	return &member, nil
+}
+
+// fetchMember gets the member from the API.
+// The user of the member is cached too, because it has the global name.
+func (s *discordService) fetchMember(guildID, userID string) (*discord.Member, error) {
+	body, err := s.session.RequestWithBucketID("GET", discord.EndpointGuildMember(guildID, userID), nil, discord.EndpointGuildMember(guildID, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	var dm discordMember
+	if err := json.Unmarshal(body, &dm); err != nil {
+		return nil, fmt.Errorf("failed to decode the member: %w", err)
+	}
+
+	m := &dm.Member
+	m.GuildID = guildID
+	if dm.User != nil {
+		m.User = &dm.User.User
+		s.users.Set(userID, dm.User)
+	}
+	if err := s.session.State.MemberAdd(m); err != nil {
+		s.l.Warn("Failed to add the member to the state", slog.String("guild_id", guildID), slog.Any("err", err))
+	}
+
+	return m, nil
+}
+
+// forgetMember removes the member and the user from the cache, because they are updated.
+func (s *discordService) forgetMember(m *discord.Member) {
+	if m == nil || m.User == nil {
+		return
+	}
+
+	s.members.Delete(m.GuildID + "/" + m.User.ID)
+	s.users.Delete(m.User.ID)
+}
+
+// roleNames returns the names of the roles of the guild.
+// Roles that are not found are returned as their IDs.
+func (s *discordService) roleNames(guildID string, roleIDs []string) []string {
+	roles := s.guildRoles(guildID)
+
+	names := make([]string, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		name := id
+		for _, r := range roles {
+			if r.ID == id {
+				name = r.Name
+				break
+			}
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// guildRoles returns the roles of the guild from the state, the cache, or the API.
+// The state does not have the guild if it is not sent by the gateway.
+func (s *discordService) guildRoles(guildID string) []*discord.Role {
+	if g, err := s.session.State.Guild(guildID); err == nil {
+		return g.Roles
+	}
+	if roles, ok := s.roles.Get(guildID); ok {
+		return roles
+	}
+
+	roles, err := s.session.GuildRoles(guildID)
+	if err != nil {
+		s.l.Error("Failed to get roles", slog.String("guild_id", guildID), slog.Any("err", err))
+		return nil
+	}
+	s.roles.Set(guildID, roles)
+
+	return roles
 }
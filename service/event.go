@@ -14,12 +14,26 @@ const (
 	ConnectedEvent
 	DisconnectedEvent
 	MessageEvent
+	DeliveryFailedEvent
+	ReactionEvent
+	MessageEditedEvent
+	MessageDeletedEvent
+	InteractionEvent
 )
 
 // Event represents service events.
 type Event struct {
 	Type EventType
 	Data interface{}
+	// Done is called when the bot has finished handling the event, if it is not nil.
+	Done func()
+}
+
+// Finish calls e.Done if it is not nil.
+func (e *Event) Finish() {
+	if e.Done != nil {
+		e.Done()
+	}
 }
 
 // GetHello returns a plugin.Hello.
@@ -31,6 +45,42 @@ func (e *Event) GetHello() plugin.Hello {
 	return nil
 }
 
+// GetDeliveryFailure returns a *plugin.DeliveryFailure.
+func (e *Event) GetDeliveryFailure() *plugin.DeliveryFailure {
+	if f, ok := e.Data.(*plugin.DeliveryFailure); ok {
+		return f
+	}
+
+	return nil
+}
+
+// GetReaction returns a plugin.Reaction.
+func (e *Event) GetReaction() plugin.Reaction {
+	if r, ok := e.Data.(plugin.Reaction); ok {
+		return r
+	}
+
+	return nil
+}
+
+// GetInteraction returns a plugin.Interaction.
+func (e *Event) GetInteraction() plugin.Interaction {
+	if i, ok := e.Data.(plugin.Interaction); ok {
+		return i
+	}
+
+	return nil
+}
+
+// GetMessageRef returns a *plugin.MessageRef.
+func (e *Event) GetMessageRef() *plugin.MessageRef {
+	if ref, ok := e.Data.(*plugin.MessageRef); ok {
+		return ref
+	}
+
+	return nil
+}
+
 // GetHello returns a plugin.Message.
 func (e *Event) GetMessage() plugin.Message {
 	if msg, ok := e.Data.(plugin.Message); ok {
@@ -12,11 +12,16 @@ func _() {
 	_ = x[ConnectedEvent-1]
 	_ = x[DisconnectedEvent-2]
 	_ = x[MessageEvent-3]
+	_ = x[DeliveryFailedEvent-4]
+	_ = x[ReactionEvent-5]
+	_ = x[MessageEditedEvent-6]
+	_ = x[MessageDeletedEvent-7]
+	_ = x[InteractionEvent-8]
 }
 
-const _EventType_name = "UnknownEventConnectedEventDisconnectedEventMessageEvent"
+const _EventType_name = "UnknownEventConnectedEventDisconnectedEventMessageEventDeliveryFailedEventReactionEventMessageEditedEventMessageDeletedEventInteractionEvent"
 
-var _EventType_index = [...]uint8{0, 12, 26, 43, 55}
+var _EventType_index = [...]uint8{0, 12, 26, 43, 55, 74, 87, 105, 124, 140}
 
 func (i EventType) String() string {
 	if i < 0 || i >= EventType(len(_EventType_index)-1) {
@@ -0,0 +1,142 @@
+// Package cache provides an in-memory cache for bot services,
+// of which entries expire after a period of time.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is a cache of values of type V with keys of type K.
+// It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	ttl  time.Duration
+	size int
+	now  func() time.Time
+
+	entries map[K]*list.Element
+	// lru is the list of entries ordered from the most recently used.
+	lru *list.List
+	// nextPurge is the time to remove expired entries next.
+	nextPurge time.Time
+	mux       sync.Mutex
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// New returns a new *Cache of which entries expire after ttl.
+func New[K comparable, V any](ttl time.Duration) *Cache[K, V] {
+	return NewLRU[K, V](ttl, 0)
+}
+
+// NewLRU returns a new *Cache of which entries expire after ttl, that has at most size entries.
+// The least recently used entry is removed when a new entry is added to the full cache.
+// The size is not limited if it is zero.
+func NewLRU[K comparable, V any](ttl time.Duration, size int) *Cache[K, V] {
+	return &Cache[K, V]{
+		ttl:     ttl,
+		size:    size,
+		now:     time.Now,
+		entries: make(map[K]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Get returns the value of the key.
+// Returns false if the value is not cached or has expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	e := elem.Value.(*entry[K, V])
+	if !c.now().Before(e.expires) {
+		c.remove(elem)
+		var zero V
+		return zero, false
+	}
+	c.lru.MoveToFront(elem)
+
+	return e.value, true
+}
+
+// Set caches the value of the key.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	now := c.now()
+	c.purge(now)
+
+	e := &entry[K, V]{
+		key:     key,
+		value:   value,
+		expires: now.Add(c.ttl),
+	}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = e
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(e)
+	if c.size > 0 && c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+// Delete removes the value of the key.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+}
+
+// Len returns the number of the cached values, including expired ones that are not removed yet.
+func (c *Cache[K, V]) Len() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.lru.Len()
+}
+
+// Full returns whether the cache has as many entries as the size.
+// It is always false if the size is not limited.
+func (c *Cache[K, V]) Full() bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.size > 0 && c.lru.Len() >= c.size
+}
+
+func (c *Cache[K, V]) remove(elem *list.Element) {
+	e := c.lru.Remove(elem).(*entry[K, V])
+	delete(c.entries, e.key)
+}
+
+// purge removes expired entries, at most once in the TTL.
+func (c *Cache[K, V]) purge(now time.Time) {
+	if now.Before(c.nextPurge) {
+		return
+	}
+	c.nextPurge = now.Add(c.ttl)
+
+	for _, elem := range c.entries {
+		if e := elem.Value.(*entry[K, V]); !now.Before(e.expires) {
+			c.remove(elem)
+		}
+	}
+}
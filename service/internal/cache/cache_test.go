@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// clock is a fake clock for tests.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestCache(ttl time.Duration) (*Cache[string, int], *clock) {
+	clk := &clock{now: time.Date(2023, 10, 1, 9, 0, 0, 0, time.UTC)}
+	c := New[string, int](ttl)
+	c.now = clk.Now
+
+	return c, clk
+}
+
+func Test_Cache(t *testing.T) {
+	c, clk := newTestCache(time.Minute)
+
+	c.Set("a", 1)
+	clk.Advance(30 * time.Second)
+	c.Set("b", 2)
+
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf(`Get("a") => %d, %v, want 1, true`, v, ok)
+	}
+	if _, ok := c.Get("c"); ok {
+		t.Error(`Get("c") => true, want false`)
+	}
+
+	clk.Advance(30 * time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Error(`Get("a") after expiration => true, want false`)
+	}
+	if v, ok := c.Get("b"); !ok || v != 2 {
+		t.Errorf(`Get("b") => %d, %v, want 2, true`, v, ok)
+	}
+
+	c.Delete("b")
+	if _, ok := c.Get("b"); ok {
+		t.Error(`Get("b") after Delete => true, want false`)
+	}
+}
+
+func Test_Cache_purge(t *testing.T) {
+	c, clk := newTestCache(time.Minute)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	clk.Advance(time.Minute)
+	c.Set("c", 3)
+
+	if n := c.Len(); n != 1 {
+		t.Errorf("Len() => %d, want 1", n)
+	}
+}
+
+func Test_Cache_LRU(t *testing.T) {
+	c := NewLRU[string, int](time.Minute, 2)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if !c.Full() {
+		t.Error("Full() => false, want true")
+	}
+
+	// "a" is used more recently than "b"
+	c.Get("a")
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error(`Get("b") => true, want false`)
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if v, ok := c.Get(key); !ok || v != want {
+			t.Errorf("Get(%q) => %d, %v, want %d, true", key, v, ok, want)
+		}
+	}
+	if n := c.Len(); n != 2 {
+		t.Errorf("Len() => %d, want 2", n)
+	}
+}
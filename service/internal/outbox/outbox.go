@@ -0,0 +1,655 @@
+// Package outbox provides a queue of outgoing messages for bot services.
+//
+// Messages to each channel are sent one by one in the order they are pushed,
+// independently of the other channels. A message that fails to be sent is
+// retried with exponential backoff, or after the wait requested by the platform
+// if it is rate limited, without blocking messages to the other channels.
+// Sending is paused while the service is disconnected.
+//
+// If the bot database is in the context passed to Start, undelivered messages
+// are saved to the database, and sent again after the bot restarts.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kechako/gopher-bot/v2/internal/database"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+)
+
+var (
+	// ErrClosed is the error of messages that are not delivered before the outbox is closed.
+	ErrClosed = errors.New("outbox is closed")
+	// ErrPending is the error of messages that are not delivered before the outbox is closed,
+	// but are saved to the database to be delivered after the bot restarts.
+	ErrPending = errors.New("outbox is closed, the message will be delivered after restart")
+)
+
+const (
+	defaultMinBackoff  = time.Second
+	defaultMaxBackoff  = time.Minute
+	defaultMaxAttempts = 10
+)
+
+// RateLimitError is the error returned by SendFunc when the platform limits the rate of requests.
+// The message is retried after RetryAfter, and the attempt is not counted.
+type RateLimitError struct {
+	// RetryAfter is the wait requested by the platform.
+	RetryAfter time.Duration
+	// Err is the error returned by the platform.
+	Err error
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s: %v", e.RetryAfter, e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err to indicate that sending the message cannot succeed by retrying,
+// e.g. the channel does not exist. The message is dropped without retrying.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Message represents an outgoing message.
+type Message struct {
+	// ChannelID is the ID of the channel that the message is posted to.
+	ChannelID string
+	// ThreadID is the ID of the thread that the message is posted to, if any.
+	ThreadID string
+	// Text is the text of the message.
+	Text string
+	// Rich is the rich message that is posted instead of Text, if any.
+	// It is not saved to the database, so restored messages are posted as Text.
+	Rich *rich.Message
+
+	// id is the ID in the database. It is zero if the message is not saved.
+	id int64
+	// delivery is the result of sending the message.
+	// It is nil if the message is restored from the database.
+	delivery *Delivery
+}
+
+// SendFunc sends the message to the chat platform, and returns the reference to the posted message.
+type SendFunc func(ctx context.Context, msg *Message) (plugin.MessageRef, error)
+
+// Delivery is the result of sending a message.
+type Delivery struct {
+	done chan struct{}
+	ref  plugin.MessageRef
+	err  error
+}
+
+func newDelivery() *Delivery {
+	return &Delivery{
+		done: make(chan struct{}),
+	}
+}
+
+func (d *Delivery) resolve(ref plugin.MessageRef, err error) {
+	if d == nil {
+		return
+	}
+
+	d.ref = ref
+	d.err = err
+	close(d.done)
+}
+
+// Wait waits until the message is delivered, and returns the reference to the posted message.
+// Returns an error if the message is dropped, or ctx is done before it is delivered.
+// The message stays in the outbox even if ctx is done.
+func (d *Delivery) Wait(ctx context.Context) (plugin.MessageRef, error) {
+	select {
+	case <-ctx.Done():
+		return plugin.MessageRef{}, ctx.Err()
+	case <-d.done:
+		return d.ref, d.err
+	}
+}
+
+type Config struct {
+	Logger *slog.Logger
+	// Name is the name of the service, used to save undelivered messages to the database.
+	// Messages are not saved if it is empty.
+	Name string
+	// MinBackoff is the wait before the first retry. The default is 1 second.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum wait between retries. The default is 1 minute.
+	MaxBackoff time.Duration
+	// MaxAttempts is the number of attempts to send a message before it is dropped.
+	// Attempts made while the service is disconnected or rate limited are not counted.
+	// The default is 10.
+	MaxAttempts int
+	// Interval is the minimum interval between messages posted to the same channel.
+	// Messages to the other channels are not delayed by it.
+	Interval time.Duration
+	// OnFailure is called when a message is dropped without being delivered.
+	OnFailure func(ctx context.Context, msg *Message, err error)
+}
+
+func (cfg *Config) logger() *slog.Logger {
+	var l *slog.Logger
+	if cfg != nil {
+		l = cfg.Logger
+	}
+	if l != nil {
+		return l
+	}
+	return slog.New(slog.NewTextHandler(os.Stdout, nil))
+}
+
+// Outbox is a queue of outgoing messages.
+type Outbox struct {
+	send SendFunc
+	l    *slog.Logger
+
+	name        string
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	maxAttempts int
+	interval    time.Duration
+	onFailure   func(ctx context.Context, msg *Message, err error)
+
+	queues    map[string]*queue
+	connected bool
+	lastSent  map[string]time.Time
+	db        *database.DB
+	mux       sync.Mutex
+
+	// reconnect is closed and replaced when the service has reconnected.
+	reconnect chan struct{}
+
+	// ctx is the context of the workers. It is nil until Start is called.
+	ctx    context.Context
+	closed bool
+	wg     sync.WaitGroup
+	exit   context.CancelFunc
+}
+
+// queue is the queue of messages posted to a channel.
+// Each queue is sent by its own worker, so that a channel waiting for a retry
+// does not block the other channels.
+type queue struct {
+	channelID string
+	messages  []*Message
+	attempts  int
+	backoff   time.Duration
+	// running is true while the worker of the queue is running.
+	running bool
+}
+
+// New returns a new *Outbox that sends messages with send.
+// The outbox is disconnected until SetConnected(true) is called.
+func New(send SendFunc, cfg *Config) *Outbox {
+	o := &Outbox{
+		send:        send,
+		l:           cfg.logger(),
+		minBackoff:  defaultMinBackoff,
+		maxBackoff:  defaultMaxBackoff,
+		maxAttempts: defaultMaxAttempts,
+		queues:      make(map[string]*queue),
+		lastSent:    make(map[string]time.Time),
+		reconnect:   make(chan struct{}),
+	}
+
+	if cfg != nil {
+		o.name = cfg.Name
+		o.interval = cfg.Interval
+		o.onFailure = cfg.OnFailure
+		if cfg.MinBackoff > 0 {
+			o.minBackoff = cfg.MinBackoff
+		}
+		if cfg.MaxBackoff > 0 {
+			o.maxBackoff = cfg.MaxBackoff
+		}
+		if cfg.MaxAttempts > 0 {
+			o.maxAttempts = cfg.MaxAttempts
+		}
+	}
+	if o.maxBackoff < o.minBackoff {
+		o.maxBackoff = o.minBackoff
+	}
+
+	return o
+}
+
+// Start starts sending messages until ctx is canceled or Close is called.
+// If the bot database is in ctx, messages saved by the previous run are
+// sent before the messages pushed to this outbox.
+func (o *Outbox) Start(ctx context.Context) {
+	if db, ok := database.FromContext(ctx); ok && o.name != "" {
+		o.restore(ctx, db)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	o.ctx = ctx
+	o.exit = cancel
+	for _, q := range o.queues {
+		o.run(q)
+	}
+}
+
+// restore loads messages saved in the database, and saves messages pushed before Start.
+func (o *Outbox) restore(ctx context.Context, db *database.DB) {
+	saved, err := db.SearchOutboxMessages(ctx, o.name)
+	if err != nil {
+		o.l.Error("failed to load undelivered messages", slog.Any("err", err))
+	}
+
+	o.mux.Lock()
+	var pushed []*Message
+	for _, q := range o.queues {
+		pushed = append(pushed, q.messages...)
+	}
+	o.db = db
+	o.mux.Unlock()
+
+	for _, msg := range pushed {
+		o.save(ctx, msg)
+	}
+
+	if len(saved) > 0 {
+		o.l.Info("restored undelivered messages", slog.Int("count", len(saved)))
+	}
+
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	restored := make(map[string][]*Message)
+	for _, m := range saved {
+		restored[m.Channel] = append(restored[m.Channel], &Message{
+			ChannelID: m.Channel,
+			ThreadID:  m.Thread,
+			Text:      m.Text,
+			id:        m.ID,
+		})
+	}
+	for channelID, messages := range restored {
+		q := o.queue(channelID)
+		q.messages = append(messages, q.messages...)
+	}
+}
+
+// Close stops sending messages.
+// Messages that are not sent yet are discarded, unless they are saved to the database.
+// Deliveries of the saved messages fail with ErrPending, and the others fail with ErrClosed.
+func (o *Outbox) Close() error {
+	o.mux.Lock()
+	o.closed = true
+	o.mux.Unlock()
+
+	if o.exit != nil {
+		o.exit()
+	}
+	o.wg.Wait()
+
+	o.mux.Lock()
+	var messages []*Message
+	for _, q := range o.queues {
+		messages = append(messages, q.messages...)
+	}
+	o.queues = make(map[string]*queue)
+	o.mux.Unlock()
+
+	var saved int
+	for _, msg := range messages {
+		if msg.id != 0 {
+			saved++
+			msg.delivery.resolve(plugin.MessageRef{}, ErrPending)
+		} else {
+			msg.delivery.resolve(plugin.MessageRef{}, ErrClosed)
+		}
+	}
+	if len(messages) > 0 {
+		o.l.Warn("outbox is closed with undelivered messages", slog.Int("count", len(messages)), slog.Int("saved", saved))
+	}
+
+	return nil
+}
+
+// Push adds the message to the end of the queue of its channel.
+// The message is saved to the database with ctx if the database is available.
+// The returned *Delivery reports the result of sending the message.
+func (o *Outbox) Push(ctx context.Context, msg *Message) *Delivery {
+	msg.delivery = newDelivery()
+
+	o.mux.Lock()
+	closed := o.closed
+	o.mux.Unlock()
+	if closed {
+		msg.delivery.resolve(plugin.MessageRef{}, ErrClosed)
+		return msg.delivery
+	}
+
+	o.save(ctx, msg)
+
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	q := o.queue(msg.ChannelID)
+	q.messages = append(q.messages, msg)
+	o.run(q)
+
+	return msg.delivery
+}
+
+// queue returns the queue of the channel, creating it if it does not exist. o.mux must be held.
+func (o *Outbox) queue(channelID string) *queue {
+	q, ok := o.queues[channelID]
+	if !ok {
+		q = &queue{
+			channelID: channelID,
+			backoff:   o.minBackoff,
+		}
+		o.queues[channelID] = q
+	}
+	return q
+}
+
+// run starts the worker of the queue if the outbox is started and the worker is not running.
+// o.mux must be held.
+func (o *Outbox) run(q *queue) {
+	if q.running || o.ctx == nil || o.closed {
+		return
+	}
+
+	q.running = true
+	o.wg.Add(1)
+	go o.worker(o.ctx, q)
+}
+
+// save saves the message to the database if the database is available.
+func (o *Outbox) save(ctx context.Context, msg *Message) {
+	o.mux.Lock()
+	db := o.db
+	o.mux.Unlock()
+
+	if db == nil {
+		return
+	}
+
+	m := &database.OutboxMessage{
+		Service: o.name,
+		Channel: msg.ChannelID,
+		Thread:  msg.ThreadID,
+		Text:    msg.Text,
+	}
+	if err := db.SaveOutboxMessage(ctx, m); err != nil {
+		o.l.Error("failed to save a message", slog.String("channel_id", msg.ChannelID), slog.Any("err", err))
+		return
+	}
+	msg.id = m.ID
+}
+
+// forget deletes the message from the database if it is saved.
+// It does not use the context of the worker, so that a delivered message is
+// deleted even if the outbox is being closed.
+func (o *Outbox) forget(msg *Message) {
+	o.mux.Lock()
+	db := o.db
+	o.mux.Unlock()
+
+	if db == nil || msg.id == 0 {
+		return
+	}
+
+	if err := db.DeleteOutboxMessage(context.Background(), msg.id); err != nil {
+		o.l.Error("failed to delete a message", slog.String("channel_id", msg.ChannelID), slog.Any("err", err))
+	}
+}
+
+// Len returns the number of messages that are not sent yet.
+func (o *Outbox) Len() int {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	var n int
+	for _, q := range o.queues {
+		n += len(q.messages)
+	}
+	return n
+}
+
+// SetConnected sets the connection state of the service.
+// Sending is paused while the service is disconnected, and the messages at the head
+// of the queues are retried immediately when the service has reconnected.
+func (o *Outbox) SetConnected(connected bool) {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	reconnected := connected && !o.connected
+	o.connected = connected
+	if !reconnected {
+		return
+	}
+
+	for _, q := range o.queues {
+		q.attempts = 0
+		q.backoff = o.minBackoff
+	}
+	close(o.reconnect)
+	o.reconnect = make(chan struct{})
+}
+
+// worker sends the messages of the queue until the queue is empty or ctx is done.
+func (o *Outbox) worker(ctx context.Context, q *queue) {
+	defer o.wg.Done()
+
+	for {
+		msg, connected, reconnect := o.head(q)
+		if msg == nil {
+			return
+		}
+		if !connected {
+			select {
+			case <-ctx.Done():
+				o.stop(q)
+				return
+			case <-reconnect:
+			}
+			continue
+		}
+
+		if wait := o.throttle(msg); wait > 0 {
+			if !o.sleep(ctx, wait, reconnect) {
+				o.stop(q)
+				return
+			}
+			continue
+		}
+
+		ref, err := o.send(ctx, msg)
+		if err == nil {
+			o.sent(q, msg, ref)
+			continue
+		}
+		if ctx.Err() != nil {
+			o.stop(q)
+			return
+		}
+
+		wait, retry := o.failed(ctx, q, msg, err)
+		if !retry {
+			continue
+		}
+
+		if !o.sleep(ctx, wait, reconnect) {
+			o.stop(q)
+			return
+		}
+	}
+}
+
+// sleep waits for d, or until the service has reconnected.
+// Returns false if ctx is done.
+func (o *Outbox) sleep(ctx context.Context, d time.Duration, reconnect <-chan struct{}) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-reconnect:
+	case <-timer.C:
+	}
+
+	return true
+}
+
+// head returns the message at the head of the queue, whether the service is connected,
+// and the channel closed when the service has reconnected.
+// Returns a nil message and removes the queue if it is empty.
+func (o *Outbox) head(q *queue) (*Message, bool, <-chan struct{}) {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	if len(q.messages) == 0 {
+		q.running = false
+		delete(o.queues, q.channelID)
+		return nil, false, nil
+	}
+
+	return q.messages[0], o.connected, o.reconnect
+}
+
+// stop marks the worker of the queue as stopped.
+func (o *Outbox) stop(q *queue) {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	q.running = false
+}
+
+// throttle returns the wait until the message can be posted to the channel.
+func (o *Outbox) throttle(msg *Message) time.Duration {
+	if o.interval <= 0 {
+		return 0
+	}
+
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	last, ok := o.lastSent[msg.ChannelID]
+	if !ok {
+		return 0
+	}
+
+	return time.Until(last.Add(o.interval))
+}
+
+// sent removes the delivered message from the database and the head of the queue.
+func (o *Outbox) sent(q *queue, msg *Message, ref plugin.MessageRef) {
+	o.forget(msg)
+	msg.delivery.resolve(ref, nil)
+
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	o.lastSent[msg.ChannelID] = time.Now()
+	o.pop(q)
+}
+
+// failed records the failure of sending msg.
+// Returns the wait before the next attempt and true if msg should be retried.
+func (o *Outbox) failed(ctx context.Context, q *queue, msg *Message, err error) (time.Duration, bool) {
+	var (
+		rateLimit *RateLimitError
+		permanent *permanentError
+	)
+	switch {
+	case errors.As(err, &rateLimit):
+		wait := rateLimit.RetryAfter
+		if wait <= 0 {
+			wait = o.minBackoff
+		}
+		o.l.Warn("rate limited, retry later",
+			slog.String("channel_id", msg.ChannelID),
+			slog.Duration("wait", wait),
+			slog.Any("err", err))
+		return wait, true
+	case errors.As(err, &permanent):
+		o.drop(ctx, q, msg, err)
+		return 0, false
+	}
+
+	o.mux.Lock()
+	if o.connected {
+		q.attempts++
+	}
+	attempts := q.attempts
+	wait := q.backoff
+	q.backoff *= 2
+	if q.backoff > o.maxBackoff {
+		q.backoff = o.maxBackoff
+	}
+	o.mux.Unlock()
+
+	if attempts >= o.maxAttempts {
+		o.drop(ctx, q, msg, err)
+		return 0, false
+	}
+
+	o.l.Warn("failed to send a message, retry later",
+		slog.String("channel_id", msg.ChannelID),
+		slog.Int("attempts", attempts),
+		slog.Duration("wait", wait),
+		slog.Any("err", err))
+
+	return wait, true
+}
+
+// drop removes the message at the head of the queue without delivering it.
+func (o *Outbox) drop(ctx context.Context, q *queue, msg *Message, err error) {
+	o.l.Error("failed to send a message, give up",
+		slog.String("channel_id", msg.ChannelID),
+		slog.Any("err", err))
+
+	o.forget(msg)
+	msg.delivery.resolve(plugin.MessageRef{}, err)
+
+	if o.onFailure != nil {
+		o.onFailure(ctx, msg, err)
+	}
+
+	o.mux.Lock()
+	o.pop(q)
+	o.mux.Unlock()
+}
+
+// pop removes the message at the head of the queue. o.mux must be held.
+func (o *Outbox) pop(q *queue) {
+	q.messages[0] = nil
+	q.messages = q.messages[1:]
+	q.attempts = 0
+	q.backoff = o.minBackoff
+}
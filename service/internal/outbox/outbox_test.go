@@ -0,0 +1,314 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kechako/gopher-bot/v2/internal/database"
+	"github.com/kechako/gopher-bot/v2/plugin"
+)
+
+var errSend = errors.New("send error")
+
+// sender records sent messages, and returns errs in order before it succeeds.
+type sender struct {
+	sent     []string
+	failures []string
+	errs     []error
+	calls    int
+	mux      sync.Mutex
+}
+
+func (s *sender) send(ctx context.Context, msg *Message) (plugin.MessageRef, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return plugin.MessageRef{}, err
+	}
+	s.sent = append(s.sent, msg.Text)
+	return plugin.MessageRef{ChannelID: msg.ChannelID, MessageID: msg.Text}, nil
+}
+
+func (s *sender) onFailure(ctx context.Context, msg *Message, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.failures = append(s.failures, msg.Text)
+}
+
+func (s *sender) result() (sent []string, failures []string, calls int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return append([]string(nil), s.sent...), append([]string(nil), s.failures...), s.calls
+}
+
+func newTestOutbox(ctx context.Context, t *testing.T, s *sender, cfg *Config) *Outbox {
+	t.Helper()
+
+	cfg.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg.MinBackoff = time.Millisecond
+	cfg.MaxBackoff = 4 * time.Millisecond
+	cfg.OnFailure = s.onFailure
+
+	o := New(s.send, cfg)
+	o.Start(ctx)
+	t.Cleanup(func() { o.Close() })
+
+	return o
+}
+
+func waitEmpty(t *testing.T, o *Outbox) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for o.Len() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("outbox has %d messages after timeout", o.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func repeat(err error, n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+func Test_Outbox(t *testing.T) {
+	t.Parallel()
+
+	rateLimit := &RateLimitError{RetryAfter: time.Millisecond, Err: errSend}
+
+	tests := map[string]struct {
+		errs     []error
+		sent     []string
+		failures []string
+		calls    int
+	}{
+		"sent in order": {
+			sent:  []string{"a", "b", "c"},
+			calls: 3,
+		},
+		"retried": {
+			errs:  repeat(errSend, 2),
+			sent:  []string{"a", "b", "c"},
+			calls: 5,
+		},
+		"dropped": {
+			errs:     repeat(errSend, 3),
+			sent:     []string{"b", "c"},
+			failures: []string{"a"},
+			calls:    5,
+		},
+		"rate limited": {
+			errs:  repeat(rateLimit, 5),
+			sent:  []string{"a", "b", "c"},
+			calls: 8,
+		},
+		"permanent": {
+			errs:     []error{Permanent(errSend)},
+			sent:     []string{"b", "c"},
+			failures: []string{"a"},
+			calls:    3,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &sender{errs: tt.errs}
+			o := newTestOutbox(context.Background(), t, s, &Config{MaxAttempts: 3})
+			o.SetConnected(true)
+
+			for _, text := range []string{"a", "b", "c"} {
+				o.Push(context.Background(), &Message{ChannelID: "C1", Text: text})
+			}
+			waitEmpty(t, o)
+
+			sent, failures, calls := s.result()
+			if diff := cmp.Diff(sent, tt.sent); diff != "" {
+				t.Errorf("sent messages differ: (-got +want)\n%s", diff)
+			}
+			if diff := cmp.Diff(failures, tt.failures); diff != "" {
+				t.Errorf("failed messages differ: (-got +want)\n%s", diff)
+			}
+			if calls != tt.calls {
+				t.Errorf("send is called %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func Test_Delivery(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	s := &sender{errs: []error{Permanent(errSend)}}
+	o := newTestOutbox(ctx, t, s, &Config{})
+
+	failed := o.Push(ctx, &Message{ChannelID: "C1", Text: "a"})
+	delivered := o.Push(ctx, &Message{ChannelID: "C1", Text: "b"})
+	o.SetConnected(true)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := failed.Wait(waitCtx); !errors.Is(err, errSend) {
+		t.Errorf("Delivery.Wait() of the dropped message => error %v, want %v", err, errSend)
+	}
+
+	ref, err := delivered.Wait(waitCtx)
+	if err != nil {
+		t.Fatalf("Delivery.Wait() => error %v", err)
+	}
+	if want := (plugin.MessageRef{ChannelID: "C1", MessageID: "b"}); ref != want {
+		t.Errorf("Delivery.Wait() => %v, want %v", ref, want)
+	}
+
+	o.SetConnected(false)
+	closed := o.Push(ctx, &Message{ChannelID: "C1", Text: "c"})
+	o.Close()
+
+	if _, err := closed.Wait(waitCtx); !errors.Is(err, ErrClosed) {
+		t.Errorf("Delivery.Wait() of the message in the closed outbox => error %v, want %v", err, ErrClosed)
+	}
+}
+
+func Test_Outbox_SetConnected(t *testing.T) {
+	t.Parallel()
+
+	s := &sender{}
+	o := newTestOutbox(context.Background(), t, s, &Config{})
+
+	o.Push(context.Background(), &Message{ChannelID: "C1", Text: "a"})
+	time.Sleep(10 * time.Millisecond)
+
+	if _, _, calls := s.result(); calls != 0 {
+		t.Fatalf("send is called %d times while disconnected", calls)
+	}
+
+	o.SetConnected(true)
+	waitEmpty(t, o)
+
+	if sent, _, _ := s.result(); !cmp.Equal(sent, []string{"a"}) {
+		t.Errorf("sent messages are %v after reconnect", sent)
+	}
+}
+
+func Test_Outbox_Interval(t *testing.T) {
+	t.Parallel()
+
+	const interval = 50 * time.Millisecond
+
+	s := &sender{}
+	o := newTestOutbox(context.Background(), t, s, &Config{Interval: interval})
+	o.SetConnected(true)
+
+	start := time.Now()
+	o.Push(context.Background(), &Message{ChannelID: "C1", Text: "a"})
+	o.Push(context.Background(), &Message{ChannelID: "C2", Text: "b"})
+	o.Push(context.Background(), &Message{ChannelID: "C1", Text: "c"})
+	waitEmpty(t, o)
+
+	if elapsed := time.Since(start); elapsed < interval {
+		t.Errorf("messages to the same channel are sent in %v, want >= %v", elapsed, interval)
+	}
+}
+
+func Test_Outbox_channels(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// messages to C1 are retried until the outbox is closed
+	s := &sender{errs: repeat(errSend, 1000)}
+	o := New(func(ctx context.Context, msg *Message) (plugin.MessageRef, error) {
+		if msg.ChannelID == "C1" {
+			return s.send(ctx, msg)
+		}
+		return plugin.MessageRef{ChannelID: msg.ChannelID, MessageID: msg.Text}, nil
+	}, &Config{
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		MinBackoff: time.Hour,
+	})
+	o.Start(ctx)
+	t.Cleanup(func() { o.Close() })
+	o.SetConnected(true)
+
+	blocked := o.Push(ctx, &Message{ChannelID: "C1", Text: "a"})
+	delivered := o.Push(ctx, &Message{ChannelID: "C2", Text: "b"})
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := delivered.Wait(waitCtx); err != nil {
+		t.Errorf("Delivery.Wait() of the message to another channel => error %v", err)
+	}
+
+	o.Close()
+	if _, err := blocked.Wait(waitCtx); !errors.Is(err, ErrClosed) {
+		t.Errorf("Delivery.Wait() of the retried message => error %v, want %v", err, ErrClosed)
+	}
+}
+
+func Test_Outbox_restore(t *testing.T) {
+	t.Parallel()
+
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx := database.ContextWithDB(context.Background(), db)
+
+	// the service is closed before it connects
+	o := New((&sender{}).send, &Config{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Name:   "test",
+	})
+	o.Push(ctx, &Message{ChannelID: "C1", Text: "a"})
+	o.Start(ctx)
+	pending := o.Push(ctx, &Message{ChannelID: "C1", Text: "b"})
+	o.Close()
+
+	if _, err := pending.Wait(ctx); !errors.Is(err, ErrPending) {
+		t.Errorf("Delivery.Wait() of the saved message in the closed outbox => error %v, want %v", err, ErrPending)
+	}
+
+	s := &sender{}
+	o = newTestOutbox(ctx, t, s, &Config{Name: "test"})
+	o.Push(ctx, &Message{ChannelID: "C1", Text: "c"})
+	o.SetConnected(true)
+	waitEmpty(t, o)
+
+	if sent, _, _ := s.result(); !cmp.Equal(sent, []string{"a", "b", "c"}) {
+		t.Errorf("sent messages are %v after restart", sent)
+	}
+
+	saved, err := db.SearchOutboxMessages(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) > 0 {
+		t.Errorf("%d messages are left in the database", len(saved))
+	}
+}
@@ -0,0 +1,175 @@
+// Package split provides splitting of long messages to fit the length limits of chat platforms.
+//
+// Texts are split on line boundaries, and code fences that are open at the end of
+// a chunk are closed, and opened again at the start of the next chunk.
+package split
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// fence is the marker of code blocks.
+	fence = "```"
+	// closeFence is appended to chunks that end in code blocks.
+	closeFence = "\n" + fence
+	// maxFenceInfo is the maximum length of the opening line of a code block that is
+	// repeated in the following chunks, to keep the language of the code block.
+	maxFenceInfo = 32
+)
+
+// Text splits the text into chunks of at most limit runes.
+// Lines that are longer than limit are split in the middle.
+// limit must be large enough to contain code fences and some text.
+// Returns the text as it is if it fits in limit.
+func Text(text string, limit int) []string {
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	s := &splitter{limit: limit}
+	for _, line := range strings.SplitAfter(text, "\n") {
+		s.writeLine(line)
+	}
+	if s.n > s.head {
+		s.chunks = append(s.chunks, strings.TrimSuffix(s.buf.String(), "\n"))
+	}
+
+	return s.chunks
+}
+
+// Unfence returns the content of the code block if the whole text is a code block,
+// such as help messages escaped by services. Otherwise returns the text as it is.
+func Unfence(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, fence) || !strings.HasSuffix(trimmed, fence) {
+		return text
+	}
+
+	first, content, ok := strings.Cut(trimmed, "\n")
+	if !ok || strings.Contains(first[len(fence):], fence) {
+		return text
+	}
+	content = strings.TrimSuffix(content, fence)
+	if strings.Contains(content, fence) {
+		return text
+	}
+
+	return content
+}
+
+type splitter struct {
+	limit  int
+	chunks []string
+
+	buf strings.Builder
+	// n is the number of runes in buf.
+	n int
+	// head is the number of runes of the reopened code fence at the start of buf.
+	head int
+	// fence is the opening line of the code block that is open, or empty.
+	fence string
+}
+
+// writeLine writes the line to the current chunk, or the next chunk if it does not fit.
+func (s *splitter) writeLine(line string) {
+	if line == "" {
+		return
+	}
+
+	after := nextFence(s.fence, line)
+	if s.n+fitSize(line)+reserve(after) > s.limit && s.n > s.head && s.reopenSize()+fitSize(line)+reserve(after) <= s.limit {
+		s.flush()
+	}
+
+	// the line does not fit even in an empty chunk, so it fills the current chunk
+	for fitSize(line) > 0 && s.n+fitSize(line)+reserve(after) > s.limit {
+		n := s.limit - s.n - reserve(s.fence)
+		if n <= 0 && s.n > s.head {
+			s.flush()
+			continue
+		}
+
+		head, tail := cut(line, max(n, 1))
+		s.write(head)
+		s.flush()
+		line = tail
+	}
+
+	s.write(line)
+	s.fence = after
+}
+
+func (s *splitter) write(text string) {
+	s.buf.WriteString(text)
+	s.n += utf8.RuneCountInString(text)
+}
+
+// flush finishes the current chunk and starts a new one.
+// If a code block is open, it is closed and opened again in the new chunk.
+func (s *splitter) flush() {
+	chunk := strings.TrimSuffix(s.buf.String(), "\n")
+	if s.fence != "" {
+		chunk += closeFence
+	}
+	s.chunks = append(s.chunks, chunk)
+
+	s.buf.Reset()
+	s.n = 0
+	s.head = 0
+	if s.fence != "" {
+		s.write(s.fence + "\n")
+		s.head = s.n
+	}
+}
+
+// reopenSize returns the number of runes at the start of the next chunk to reopen the code block.
+func (s *splitter) reopenSize() int {
+	if s.fence == "" {
+		return 0
+	}
+	return utf8.RuneCountInString(s.fence) + 1
+}
+
+// fitSize returns the number of runes of the line that must fit in a chunk.
+// The trailing newline is not counted, because it is trimmed at the end of chunks.
+func fitSize(line string) int {
+	return utf8.RuneCountInString(strings.TrimSuffix(line, "\n"))
+}
+
+// reserve returns the number of runes to reserve in a chunk to close the code block.
+func reserve(fence string) int {
+	if fence == "" {
+		return 0
+	}
+	return len(closeFence)
+}
+
+// nextFence returns the opening line of the code block that is open after the line.
+func nextFence(current, line string) string {
+	if strings.Count(line, fence)%2 == 0 {
+		return current
+	}
+	if current != "" {
+		return ""
+	}
+
+	opening := strings.TrimSpace(line)
+	if !strings.HasPrefix(opening, fence) || strings.ContainsAny(opening[len(fence):], " `") || len(opening) > maxFenceInfo {
+		return fence
+	}
+
+	return opening
+}
+
+// cut cuts s after n runes.
+func cut(s string, n int) (head, tail string) {
+	i := 0
+	for ; n > 0 && i < len(s); n-- {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+
+	return s[:i], s[i:]
+}
@@ -0,0 +1,119 @@
+package split
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var textTests = map[string]struct {
+	text   string
+	limit  int
+	chunks []string
+}{
+	"short": {
+		text:   "hello\nworld",
+		limit:  20,
+		chunks: []string{"hello\nworld"},
+	},
+	"lines": {
+		text:   "aaaa\nbbbb\ncccc\ndddd",
+		limit:  10,
+		chunks: []string{"aaaa\nbbbb", "cccc\ndddd"},
+	},
+	"long line": {
+		text:   "aaaaaaaaaaaaaaa\nbb",
+		limit:  10,
+		chunks: []string{"aaaaaaaaaa", "aaaaa\nbb"},
+	},
+	"multibyte": {
+		text:   "あいうえおかきくけこ\nさしす",
+		limit:  10,
+		chunks: []string{"あいうえおかきくけこ", "さしす"},
+	},
+	"code block": {
+		text:   "```\naaaa\nbbbb\ncccc\n```",
+		limit:  14,
+		chunks: []string{"```\naaaa\n```", "```\nbbbb\n```", "```\ncccc\n```"},
+	},
+	"language": {
+		text:   "text\n```go\naaaa\nbbbb\n```\ntext",
+		limit:  20,
+		chunks: []string{"text\n```go\naaaa\n```", "```go\nbbbb\n```\ntext"},
+	},
+	"inline code": {
+		text:   "```a```\nbbbb\ncccc",
+		limit:  12,
+		chunks: []string{"```a```\nbbbb", "cccc"},
+	},
+	"long line in code block": {
+		text:   "```\naaaaaaaaaaaa\n```",
+		limit:  14,
+		chunks: []string{"```\naaaaaa\n```", "```\naaaaaa\n```"},
+	},
+}
+
+func Test_Text(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range textTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			chunks := Text(tt.text, tt.limit)
+			if diff := cmp.Diff(chunks, tt.chunks); diff != "" {
+				t.Errorf("Text(%q, %d) differs: (-got +want)\n%s", tt.text, tt.limit, diff)
+			}
+			for _, c := range chunks {
+				if n := utf8.RuneCountInString(c); n > tt.limit {
+					t.Errorf("chunk %q has %d runes, want at most %d", c, n, tt.limit)
+				}
+				if strings.Count(c, fence)%2 != 0 {
+					t.Errorf("chunk %q has unbalanced code fences", c)
+				}
+			}
+		})
+	}
+}
+
+func Test_Text_limits(t *testing.T) {
+	t.Parallel()
+
+	text := strings.Repeat("usage:\n```go\ncron add <name> <schedule> <command>\n\ncron list\n```\n", 20)
+	for limit := 16; limit <= 100; limit++ {
+		for _, c := range Text(text, limit) {
+			if n := utf8.RuneCountInString(c); n > limit {
+				t.Errorf("limit %d: chunk %q has %d runes", limit, c, n)
+			}
+			if strings.Count(c, fence)%2 != 0 {
+				t.Errorf("limit %d: chunk %q has unbalanced code fences", limit, c)
+			}
+		}
+	}
+}
+
+var unfenceTests = map[string]struct {
+	text string
+	want string
+}{
+	"code block":   {text: "```\nhelp\n```", want: "help\n"},
+	"language":     {text: "```go\nfunc main() {}\n```\n", want: "func main() {}\n"},
+	"plain":        {text: "help", want: "help"},
+	"two blocks":   {text: "```\na\n```\n```\nb\n```", want: "```\na\n```\n```\nb\n```"},
+	"inline code":  {text: "```a``` and ```b```", want: "```a``` and ```b```"},
+	"text outside": {text: "```\na\n```\nb", want: "```\na\n```\nb"},
+}
+
+func Test_Unfence(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range unfenceTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			if got := Unfence(tt.text); got != tt.want {
+				t.Errorf("Unfence(%q) => %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,11 +3,20 @@ package service
 
 import (
 	"context"
+	"errors"
+	"io"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
 )
 
+// ErrClosed is the error returned when the service is closed.
+var ErrClosed = errors.New("service is closed")
+
 // Service is the interface implemented by types that provides bot functions.
 type Service interface {
 	// Start starts the bot service.
+	// The service closes the returned channel when it stops by itself, e.g. the input has ended.
 	Start(ctx context.Context) (<-chan *Event, error)
 	// Close closes a bot session.
 	Close() error
@@ -20,3 +29,75 @@ type Service interface {
 	// EscapeHelp escapes help document.
 	EscapeHelp(help string) string
 }
+
+// ContextPoster is the interface implemented by services that can report
+// whether messages are delivered.
+type ContextPoster interface {
+	// PostContext posts a new message to the channel, and waits until it is delivered.
+	// Returns the reference to the posted message.
+	PostContext(ctx context.Context, channelID string, text string) (plugin.MessageRef, error)
+	// MentionContext posts a new message that mentions to the user to the channel,
+	// and waits until it is delivered.
+	// Returns the reference to the posted message.
+	MentionContext(ctx context.Context, channelID, userID, text string) (plugin.MessageRef, error)
+}
+
+// RichPoster is the interface implemented by services that can post rich messages.
+type RichPoster interface {
+	// PostRich posts a new rich message to the channel.
+	PostRich(channelID string, msg *rich.Message)
+	// PostRichContext posts a new rich message to the channel, and waits until it is delivered.
+	// Returns the reference to the posted message.
+	PostRichContext(ctx context.Context, channelID string, msg *rich.Message) (plugin.MessageRef, error)
+}
+
+// Uploader is the interface implemented by services that can upload files.
+type Uploader interface {
+	// Upload uploads the content of r as a file named name to the channel.
+	// comment is posted with the file, if it is not empty.
+	Upload(channelID, name string, r io.Reader, comment string) error
+}
+
+// Editor is the interface implemented by services that can edit and delete
+// messages posted by the bot.
+type Editor interface {
+	// Edit replaces the text of the message posted by the bot.
+	Edit(ref plugin.MessageRef, text string) error
+	// Delete deletes the message posted by the bot.
+	Delete(ref plugin.MessageRef) error
+}
+
+// ThreadPoster is the interface implemented by services that support threads.
+type ThreadPoster interface {
+	// PostToThread posts a new message to the thread of the channel.
+	// If threadID is empty, the message is posted to the channel.
+	PostToThread(channelID, threadID, text string)
+}
+
+// DirectMessenger is the interface implemented by services that can send direct messages.
+type DirectMessenger interface {
+	// DirectMessage posts a new message to the direct message channel with the user.
+	DirectMessage(userID, text string) error
+}
+
+// Reactor is the interface implemented by services that can add reactions to messages.
+type Reactor interface {
+	// React adds a reaction of the emoji to the message.
+	React(ref plugin.MessageRef, emoji string) error
+}
+
+// EphemeralHelper is the interface implemented by services that can post help messages
+// visible only to the user that requested them.
+type EphemeralHelper interface {
+	// SetEphemeralHelp sets whether plugin.Message.PostHelp posts help messages ephemerally.
+	// It is called before Start.
+	SetEphemeralHelp(ephemeral bool)
+}
+
+// CommandRegistrar is the interface implemented by services that register commands
+// of plugins to the chat platform, such as Discord application commands.
+type CommandRegistrar interface {
+	// RegisterCommands registers the commands in the helps of the plugins,
+	// and removes the commands registered before that are not in helps.
+	RegisterCommands(ctx context.Context, helps []*plugin.Help) error
+}
@@ -0,0 +1,42 @@
+package slack
+
+import (
+	"context"
+	"io"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/slack-go/slack/slackevents"
+)
+
+type attachment struct {
+	service *slackService
+	file    *slackevents.File
+}
+
+var _ plugin.Attachment = (*attachment)(nil)
+
+// Name implements the plugin.Attachment interface.
+func (a *attachment) Name() string {
+	return a.file.Name
+}
+
+// MIMEType implements the plugin.Attachment interface.
+func (a *attachment) MIMEType() string {
+	return a.file.Mimetype
+}
+
+// Size implements the plugin.Attachment interface.
+func (a *attachment) Size() int64 {
+	return int64(a.file.Size)
+}
+
+// Open implements the plugin.Attachment interface.
+// The file is downloaded with the token of the bot while the reader is read.
+func (a *attachment) Open(ctx context.Context) (io.ReadCloser, error) {
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(a.service.client.GetFileContext(ctx, a.file.URLPrivateDownload, w))
+	}()
+
+	return r, nil
+}
@@ -1,22 +1,36 @@
 package slack
 
 import (
+	"context"
+
 	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
 )
 
 type commandMessage struct {
 	service   *slackService
 	channelID string
+	threadID  string
 	command   string
 }
 
-var _ plugin.Message = (*message)(nil)
+var (
+	_ plugin.Message            = (*commandMessage)(nil)
+	_ plugin.ContextReplier     = (*commandMessage)(nil)
+	_ plugin.Reactor            = (*commandMessage)(nil)
+	_ plugin.ThreadReplier      = (*commandMessage)(nil)
+	_ plugin.DirectChecker      = (*commandMessage)(nil)
+	_ plugin.EphemeralReplier   = (*commandMessage)(nil)
+	_ plugin.RichReplier        = (*commandMessage)(nil)
+	_ plugin.AttachmentProvider = (*commandMessage)(nil)
+)
 
-// newMessage returns a new *message as plugin.Message.
-func newCommandMessage(service *slackService, channelID string, command string) plugin.Message {
+// newCommandMessage returns a new *commandMessage as plugin.Message.
+func newCommandMessage(service *slackService, channelID, threadID, command string) plugin.Message {
 	return &commandMessage{
 		service:   service,
 		channelID: channelID,
+		threadID:  threadID,
 		command:   command,
 	}
 }
@@ -31,19 +45,71 @@ func (m *commandMessage) UserID() string {
 	return m.service.UserID()
 }
 
+// IsDirect implements the plugin.DirectChecker interface.
+func (m *commandMessage) IsDirect() bool {
+	return isDirectChannel(m.channelID)
+}
+
+// ThreadID implements the plugin.ThreadReplier interface.
+func (m *commandMessage) ThreadID() string {
+	return m.threadID
+}
+
 // Text implements the plugin.Message interface.
 func (m *commandMessage) Text() string {
 	return m.command
 }
 
+// Attachments implements the plugin.AttachmentProvider interface.
+func (m *commandMessage) Attachments() []plugin.Attachment {
+	return nil
+}
+
 // Post implements the plugin.Message interface.
 func (m *commandMessage) Post(text string) {
-	m.service.Post(m.ChannelID(), text)
+	m.service.PostToThread(m.ChannelID(), m.ThreadID(), text)
 }
 
 // Mention implements the plugin.Message interface.
 func (m *commandMessage) Mention(text string) {
-	m.service.Mention(m.ChannelID(), m.UserID(), text)
+	m.service.MentionToThread(m.ChannelID(), m.ThreadID(), m.UserID(), text)
+}
+
+// ReplyInThread implements the plugin.ThreadReplier interface.
+// The command is not a posted message, so a new thread cannot be started from it.
+func (m *commandMessage) ReplyInThread(text string) {
+	m.Post(text)
+}
+
+// PostToChannel implements the plugin.ThreadReplier interface.
+func (m *commandMessage) PostToChannel(text string) {
+	m.service.Post(m.ChannelID(), text)
+}
+
+// PostEphemeral implements the plugin.EphemeralReplier interface.
+// The command is requested by the bot itself, so the message is posted to everyone.
+func (m *commandMessage) PostEphemeral(text string) {
+	m.Post(text)
+}
+
+// PostRich implements the plugin.RichReplier interface.
+func (m *commandMessage) PostRich(msg *rich.Message) {
+	m.service.PostRichToThread(m.ChannelID(), m.ThreadID(), msg)
+}
+
+// PostContext implements the plugin.ContextReplier interface.
+func (m *commandMessage) PostContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	return m.service.PostToThreadContext(ctx, m.ChannelID(), m.ThreadID(), text)
+}
+
+// MentionContext implements the plugin.ContextReplier interface.
+func (m *commandMessage) MentionContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	return m.service.MentionToThreadContext(ctx, m.ChannelID(), m.ThreadID(), m.UserID(), text)
+}
+
+// React implements the plugin.Reactor interface.
+func (m *commandMessage) React(ref plugin.MessageRef, emoji string) error {
+	return m.service.React(ref, emoji)
 }
 
 // Mentions implements the plugin.Message interface.
@@ -57,8 +123,13 @@ func (m *commandMessage) MentionTo(userID string) bool {
 }
 
 // PostHelp implements the plugin.Message interface.
+// Help messages are posted ephemerally if the service is set to do so.
 func (m *commandMessage) PostHelp(help *plugin.Help) {
 	msg := m.service.EscapeHelp(help.String())
 
+	if m.service.ephemeralHelp {
+		m.PostEphemeral(msg)
+		return
+	}
 	m.Post(msg)
 }
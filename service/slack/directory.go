@@ -0,0 +1,184 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kechako/gopher-bot/v2/service/internal/cache"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// defaultDirectoryCacheSize is the default number of users and channels to cache.
+	defaultDirectoryCacheSize = 5000
+	// defaultDirectoryCacheTTL is the default duration to cache users and channels.
+	defaultDirectoryCacheTTL = time.Hour
+	// directoryPageSize is the number of users or channels to get in a request of the list APIs.
+	directoryPageSize = 200
+)
+
+// directory caches users and channels of the workspace,
+// so that mentions in messages are resolved without calling the API each time.
+type directory struct {
+	client   *slack.Client
+	users    *cache.Cache[string, *slack.User]
+	channels *cache.Cache[string, *slack.Channel]
+	l        *slog.Logger
+}
+
+// newDirectory returns a new *directory that caches at most size users and size channels for ttl.
+func newDirectory(client *slack.Client, size int, ttl time.Duration, l *slog.Logger) *directory {
+	return &directory{
+		client:   client,
+		users:    cache.NewLRU[string, *slack.User](ttl, size),
+		channels: cache.NewLRU[string, *slack.Channel](ttl, size),
+		l:        l,
+	}
+}
+
+// user returns the user from the cache, or from users.info if it is not cached.
+func (d *directory) user(ctx context.Context, userID string) (*slack.User, error) {
+	if u, ok := d.users.Get(userID); ok {
+		return u, nil
+	}
+
+	u, err := d.client.GetUserInfoContext(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	d.users.Set(userID, u)
+
+	return u, nil
+}
+
+// channel returns the channel from the cache, or from conversations.info if it is not cached.
+func (d *directory) channel(ctx context.Context, channelID string) (*slack.Channel, error) {
+	if ch, ok := d.channels.Get(channelID); ok {
+		return ch, nil
+	}
+
+	ch, err := d.client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{
+		ChannelID: channelID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.channels.Set(channelID, ch)
+
+	return ch, nil
+}
+
+// setUser replaces the cached user with u, that is sent by the user_change event.
+func (d *directory) setUser(u *slack.User) {
+	d.users.Set(u.ID, u)
+}
+
+// forgetChannel removes the channel from the cache, because it is renamed.
+func (d *directory) forgetChannel(channelID string) {
+	d.channels.Delete(channelID)
+}
+
+// warm fills the cache with users from users.list and public channels from conversations.list,
+// until the cache is full.
+func (d *directory) warm(ctx context.Context) {
+	if err := d.warmUsers(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		d.l.Warn("Failed to cache users", slog.Any("err", err))
+	}
+	if err := d.warmChannels(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		d.l.Warn("Failed to cache channels", slog.Any("err", err))
+	}
+
+	d.l.Info("directory is warmed", slog.Int("users", d.users.Len()), slog.Int("channels", d.channels.Len()))
+}
+
+func (d *directory) warmUsers(ctx context.Context) error {
+	var err error
+	p := d.client.GetUsersPaginated(slack.GetUsersOptionLimit(directoryPageSize))
+	for !d.users.Full() {
+		p, err = p.Next(ctx)
+		if err != nil {
+			if waitErr := waitRateLimit(ctx, err); waitErr != nil {
+				return p.Failure(waitErr)
+			}
+			continue
+		}
+
+		for i := range p.Users {
+			if u := &p.Users[i]; !u.Deleted {
+				d.users.Set(u.ID, u)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *directory) warmChannels(ctx context.Context) error {
+	params := &slack.GetConversationsParameters{
+		ExcludeArchived: true,
+		Limit:           directoryPageSize,
+		Types:           []string{"public_channel"},
+	}
+	for !d.channels.Full() {
+		channels, cursor, err := d.client.GetConversationsContext(ctx, params)
+		if err != nil {
+			if waitErr := waitRateLimit(ctx, err); waitErr != nil {
+				return waitErr
+			}
+			continue
+		}
+
+		for i := range channels {
+			d.channels.Set(channels[i].ID, &channels[i])
+		}
+
+		if cursor == "" {
+			break
+		}
+		params.Cursor = cursor
+	}
+
+	return nil
+}
+
+// waitRateLimit waits until the rate limit is reset if err is *slack.RateLimitedError.
+// Returns err if it is another error.
+func waitRateLimit(ctx context.Context, err error) error {
+	var rateLimited *slack.RateLimitedError
+	if !errors.As(err, &rateLimited) {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(rateLimited.RetryAfter):
+		return nil
+	}
+}
+
+// userChangeEvent is the user_change event, that slackevents does not support.
+type userChangeEvent struct {
+	Event struct {
+		Type string      `json:"type"`
+		User *slack.User `json:"user"`
+	} `json:"event"`
+}
+
+// parseUserChangeEvent returns the changed user if payload is the user_change event.
+// Returns nil if it is another event.
+func parseUserChangeEvent(payload json.RawMessage) (*slack.User, error) {
+	var ev userChangeEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil, fmt.Errorf("failed to decode the event: %w", err)
+	}
+	if ev.Event.Type != "user_change" || ev.Event.User == nil {
+		return nil, nil
+	}
+
+	return ev.Event.User, nil
+}
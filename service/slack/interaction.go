@@ -0,0 +1,47 @@
+package slack
+
+import (
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+)
+
+type interaction struct {
+	service  *slackService
+	userID   string
+	actionID string
+	value    string
+	target   plugin.MessageRef
+	threadID string
+}
+
+var _ plugin.Interaction = (*interaction)(nil)
+
+// UserID implements the plugin.Interaction interface.
+func (i *interaction) UserID() string {
+	return i.userID
+}
+
+// ActionID implements the plugin.Interaction interface.
+func (i *interaction) ActionID() string {
+	return i.actionID
+}
+
+// Value implements the plugin.Interaction interface.
+func (i *interaction) Value() string {
+	return i.value
+}
+
+// Target implements the plugin.Interaction interface.
+func (i *interaction) Target() plugin.MessageRef {
+	return i.target
+}
+
+// Update implements the plugin.Interaction interface.
+func (i *interaction) Update(msg *rich.Message) error {
+	return i.service.updateRich(i.target, msg)
+}
+
+// PostEphemeral implements the plugin.Interaction interface.
+func (i *interaction) PostEphemeral(text string) {
+	i.service.PostEphemeral(i.target.ChannelID, i.threadID, i.userID, text)
+}
@@ -1,10 +1,12 @@
 package slack
 
 import (
+	"context"
 	"log/slog"
 	"strings"
 
 	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
 	"github.com/kechako/gopher-bot/v2/service/slack/internal/msgfmt"
 	"github.com/slack-go/slack/slackevents"
 )
@@ -17,10 +19,19 @@ type message struct {
 	text     string
 }
 
-var _ plugin.Message = (*message)(nil)
+var (
+	_ plugin.Message            = (*message)(nil)
+	_ plugin.ContextReplier     = (*message)(nil)
+	_ plugin.Reactor            = (*message)(nil)
+	_ plugin.ThreadReplier      = (*message)(nil)
+	_ plugin.DirectChecker      = (*message)(nil)
+	_ plugin.EphemeralReplier   = (*message)(nil)
+	_ plugin.RichReplier        = (*message)(nil)
+	_ plugin.AttachmentProvider = (*message)(nil)
+)
 
-// newMessage returns a new *message as plugin.Message.
-func newMessage(service *slackService, msg *slackevents.MessageEvent) plugin.Message {
+// newMessage returns a new *message.
+func newMessage(service *slackService, msg *slackevents.MessageEvent) *message {
 	m := &message{
 		service: service,
 		msg:     msg,
@@ -35,11 +46,20 @@ func (m *message) init() {
 	var s strings.Builder
 	for _, b := range m.blocks {
 		switch b.Type {
-		case msgfmt.ChannelBlock, msgfmt.UserBlock:
+		case msgfmt.ChannelBlock:
 			if b.Label == "" {
-				user, err := m.service.client.GetUserInfo(b.Content)
+				ch, err := m.service.directory.channel(context.Background(), b.Content)
 				if err != nil {
-					m.service.l.Error("failed to get user info", slog.String("content", b.Content))
+					m.service.l.Error("failed to get channel info", slog.String("channel_id", b.Content), slog.Any("err", err))
+				} else {
+					b.Label = ch.Name
+				}
+			}
+		case msgfmt.UserBlock:
+			if b.Label == "" {
+				user, err := m.service.directory.user(context.Background(), b.Content)
+				if err != nil {
+					m.service.l.Error("failed to get user info", slog.String("user_id", b.Content), slog.Any("err", err))
 				} else {
 					b.Label = user.Name
 				}
@@ -66,19 +86,87 @@ func (m *message) UserID() string {
 	return m.msg.User
 }
 
+// IsDirect implements the plugin.DirectChecker interface.
+func (m *message) IsDirect() bool {
+	return m.msg.ChannelType == "im"
+}
+
+// ThreadID implements the plugin.ThreadReplier interface.
+// It is the timestamp of the parent message.
+func (m *message) ThreadID() string {
+	return m.msg.ThreadTimeStamp
+}
+
 // Text implements the plugin.Message interface.
 func (m *message) Text() string {
 	return m.text
 }
 
+// Attachments implements the plugin.AttachmentProvider interface.
+func (m *message) Attachments() []plugin.Attachment {
+	if len(m.msg.Files) == 0 {
+		return nil
+	}
+
+	attachments := make([]plugin.Attachment, len(m.msg.Files))
+	for i := range m.msg.Files {
+		attachments[i] = &attachment{
+			service: m.service,
+			file:    &m.msg.Files[i],
+		}
+	}
+
+	return attachments
+}
+
 // Post implements the plugin.Message interface.
 func (m *message) Post(text string) {
-	m.service.PostToThread(m.ChannelID(), text, m.msg.ThreadTimeStamp)
+	m.service.PostToThread(m.ChannelID(), m.ThreadID(), text)
 }
 
 // Mention implements the plugin.Message interface.
 func (m *message) Mention(text string) {
-	m.service.MentionToThread(m.ChannelID(), m.UserID(), text, m.msg.ThreadTimeStamp)
+	m.service.MentionToThread(m.ChannelID(), m.ThreadID(), m.UserID(), text)
+}
+
+// ReplyInThread implements the plugin.ThreadReplier interface.
+func (m *message) ReplyInThread(text string) {
+	threadID := m.ThreadID()
+	if threadID == "" {
+		threadID = m.msg.TimeStamp
+	}
+
+	m.service.PostToThread(m.ChannelID(), threadID, text)
+}
+
+// PostToChannel implements the plugin.ThreadReplier interface.
+func (m *message) PostToChannel(text string) {
+	m.service.Post(m.ChannelID(), text)
+}
+
+// PostEphemeral implements the plugin.EphemeralReplier interface.
+func (m *message) PostEphemeral(text string) {
+	m.service.PostEphemeral(m.ChannelID(), m.ThreadID(), m.UserID(), text)
+}
+
+// PostRich implements the plugin.RichReplier interface.
+func (m *message) PostRich(msg *rich.Message) {
+	m.service.PostRichToThread(m.ChannelID(), m.ThreadID(), msg)
+}
+
+// PostContext implements the plugin.ContextReplier interface.
+func (m *message) PostContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	return m.service.PostToThreadContext(ctx, m.ChannelID(), m.ThreadID(), text)
+}
+
+// MentionContext implements the plugin.ContextReplier interface.
+func (m *message) MentionContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	return m.service.MentionToThreadContext(ctx, m.ChannelID(), m.ThreadID(), m.UserID(), text)
+}
+
+// React implements the plugin.Reactor interface.
+func (m *message) React(ref plugin.MessageRef, emoji string) error {
+	return m.service.React(ref, emoji)
 }
 
 // Mentions implements the plugin.Message interface.
@@ -106,8 +194,13 @@ func (m *message) MentionTo(userID string) bool {
 }
 
 // PostHelp implements the plugin.Message interface.
+// Help messages are posted ephemerally if the service is set to do so.
 func (m *message) PostHelp(help *plugin.Help) {
 	msg := m.service.EscapeHelp(help.String())
 
+	if m.service.ephemeralHelp {
+		m.PostEphemeral(msg)
+		return
+	}
 	m.Post(msg)
 }
@@ -0,0 +1,93 @@
+package slack
+
+import (
+	"strings"
+
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+	"github.com/slack-go/slack"
+)
+
+// maxSectionFields is the maximum number of fields in a section block.
+const maxSectionFields = 10
+
+// renderBlocks renders the rich message to Block Kit blocks.
+func renderBlocks(msg *rich.Message) []slack.Block {
+	blocks := make([]slack.Block, 0, len(msg.Blocks))
+	for _, b := range msg.Blocks {
+		switch b.Type {
+		case rich.SectionBlock:
+			text := b.Text
+			if b.Title != "" {
+				text = "*" + b.Title + "*\n" + text
+			}
+			blocks = append(blocks, slack.NewSectionBlock(markdown(text), nil, nil))
+		case rich.FieldsBlock:
+			for i := 0; i < len(b.Fields); i += maxSectionFields {
+				end := min(i+maxSectionFields, len(b.Fields))
+				fields := make([]*slack.TextBlockObject, 0, end-i)
+				for _, f := range b.Fields[i:end] {
+					fields = append(fields, markdown("*"+f.Name+"*\n"+f.Value))
+				}
+				blocks = append(blocks, slack.NewSectionBlock(nil, fields, nil))
+			}
+		case rich.CodeBlock:
+			// Slack does not support syntax highlighting, so the language is dropped.
+			code := strings.TrimSuffix(b.Text, "\n")
+			blocks = append(blocks, slack.NewSectionBlock(markdown("```\n"+code+"\n```"), nil, nil))
+		case rich.ImageBlock:
+			altText := b.Text
+			if altText == "" {
+				altText = b.URL
+			}
+			blocks = append(blocks, slack.NewImageBlock(b.URL, altText, "", nil))
+		case rich.ContextBlock:
+			blocks = append(blocks, slack.NewContextBlock("", markdown(b.Text)))
+		case rich.ActionsBlock:
+			elements := make([]slack.BlockElement, 0, len(b.Buttons))
+			for _, btn := range b.Buttons {
+				elements = append(elements, renderButton(btn))
+			}
+			blocks = append(blocks, slack.NewActionBlock("", elements...))
+		case rich.DividerBlock:
+			blocks = append(blocks, slack.NewDividerBlock())
+		case rich.SelectBlock:
+			blocks = append(blocks, slack.NewActionBlock("", renderSelect(b)))
+		}
+	}
+
+	return blocks
+}
+
+func renderButton(btn *rich.Button) *slack.ButtonBlockElement {
+	e := slack.NewButtonBlockElement(btn.ActionID, btn.Value,
+		slack.NewTextBlockObject(slack.PlainTextType, btn.Label, true, false))
+	e.URL = btn.URL
+
+	switch btn.Style {
+	case rich.PrimaryButton:
+		e.WithStyle(slack.StylePrimary)
+	case rich.DangerButton:
+		e.WithStyle(slack.StyleDanger)
+	}
+
+	return e
+}
+
+func renderSelect(b *rich.Block) *slack.SelectBlockElement {
+	var placeholder *slack.TextBlockObject
+	if b.Text != "" {
+		placeholder = slack.NewTextBlockObject(slack.PlainTextType, b.Text, true, false)
+	}
+
+	options := make([]*slack.OptionBlockObject, 0, len(b.Options))
+	for _, o := range b.Options {
+		options = append(options, slack.NewOptionBlockObject(o.Value,
+			slack.NewTextBlockObject(slack.PlainTextType, o.Label, true, false), nil))
+	}
+
+	return slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, placeholder, b.ActionID, options...)
+}
+
+func markdown(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.MarkdownType, text, false, false)
+}
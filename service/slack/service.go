@@ -4,15 +4,22 @@ package slack
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
 	"github.com/kechako/gopher-bot/v2/service"
+	"github.com/kechako/gopher-bot/v2/service/internal/outbox"
+	"github.com/kechako/gopher-bot/v2/service/internal/split"
 	"github.com/kechako/gopher-bot/v2/service/slack/internal/msgfmt"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
@@ -21,6 +28,21 @@ import (
 
 type Config struct {
 	Logger *slog.Logger
+	// SlashCommands maps slash commands to plugin commands, e.g. "/schedule" to "cron".
+	// A slash command is passed to plugins as a message of the plugin command followed by
+	// the text of the slash command. Slash commands that are not in the map are passed
+	// as the commands without the leading slash, e.g. "/cron list" as "cron list".
+	SlashCommands map[string]string
+	// MaxSplitMessages is the maximum number of messages that a long text is split into.
+	// Texts that need more messages are uploaded as a text file instead.
+	// Texts are always split if it is zero.
+	MaxSplitMessages int
+	// DirectoryCacheSize is the maximum number of users, and of channels, to cache.
+	// The default is 5000.
+	DirectoryCacheSize int
+	// DirectoryCacheTTL is the duration to cache users and channels.
+	// The default is 1 hour.
+	DirectoryCacheTTL time.Duration
 }
 
 func (cfg *Config) logger() *slog.Logger {
@@ -42,14 +64,35 @@ type slackService struct {
 	userID string
 	teamID string
 
+	outbox    *outbox.Outbox
+	directory *directory
+	connected bool
+
+	slashCommands    map[string]string
+	maxSplitMessages int
+	ephemeralHelp    bool
+
 	l *slog.Logger
 
 	ch chan *service.Event
 
 	wg   sync.WaitGroup
+	done <-chan struct{}
 	exit context.CancelFunc
 }
 
+var (
+	_ service.Service         = (*slackService)(nil)
+	_ service.ContextPoster   = (*slackService)(nil)
+	_ service.Editor          = (*slackService)(nil)
+	_ service.Reactor         = (*slackService)(nil)
+	_ service.ThreadPoster    = (*slackService)(nil)
+	_ service.DirectMessenger = (*slackService)(nil)
+	_ service.RichPoster      = (*slackService)(nil)
+	_ service.Uploader        = (*slackService)(nil)
+	_ service.EphemeralHelper = (*slackService)(nil)
+)
+
 // New returns a new Slack service as service.Service.
 func New(token, appToken string, cfg *Config) (service.Service, error) {
 	if token == "" {
@@ -63,6 +106,25 @@ func New(token, appToken string, cfg *Config) (service.Service, error) {
 		socket: socketmode.New(client),
 		l:      cfg.logger(),
 	}
+	size, ttl := defaultDirectoryCacheSize, defaultDirectoryCacheTTL
+	if cfg != nil {
+		s.slashCommands = cfg.SlashCommands
+		s.maxSplitMessages = cfg.MaxSplitMessages
+		if cfg.DirectoryCacheSize > 0 {
+			size = cfg.DirectoryCacheSize
+		}
+		if cfg.DirectoryCacheTTL > 0 {
+			ttl = cfg.DirectoryCacheTTL
+		}
+	}
+	s.directory = newDirectory(client, size, ttl, s.l)
+	s.outbox = outbox.New(s.deliver, &outbox.Config{
+		Logger: s.l,
+		Name:   "slack",
+		// chat.postMessage allows one message per second per channel
+		Interval:  time.Second,
+		OnFailure: s.handleDeliveryFailure,
+	})
 
 	return s, nil
 }
@@ -84,11 +146,20 @@ func (s *slackService) Start(ctx context.Context) (<-chan *service.Event, error)
 	s.ch = make(chan *service.Event)
 
 	ctx, cancel := context.WithCancel(ctx)
+	s.done = ctx.Done()
 	s.exit = cancel
 
+	s.outbox.Start(ctx)
+
 	s.wg.Add(1)
 	go s.loop(ctx)
 
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.directory.warm(ctx)
+	}()
+
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
@@ -119,6 +190,10 @@ loop:
 				s.l.Info("bot has connected")
 			case socketmode.EventTypeDisconnect:
 				s.l.Info("bot has disconnected")
+				s.handleDisconnect()
+			case socketmode.EventTypeConnectionError:
+				s.l.Info("bot failed to connect")
+				s.handleDisconnect()
 			case socketmode.EventTypeIncomingError:
 				s.l.Info("bot received invomming error")
 			case socketmode.EventTypeEventsAPI:
@@ -138,30 +213,242 @@ loop:
 						continue
 					}
 					s.handleMessage(ev)
+				case slackevents.ReactionAdded:
+					ev, ok := innerEvent.Data.(*slackevents.ReactionAddedEvent)
+					if !ok {
+						continue
+					}
+					s.handleReaction(ev.User, ev.Reaction, ev.Item, true)
+				case slackevents.ReactionRemoved:
+					ev, ok := innerEvent.Data.(*slackevents.ReactionRemovedEvent)
+					if !ok {
+						continue
+					}
+					s.handleReaction(ev.User, ev.Reaction, ev.Item, false)
+				case slackevents.ChannelRename:
+					ev, ok := innerEvent.Data.(*slackevents.ChannelRenameEvent)
+					if !ok {
+						continue
+					}
+					s.directory.forgetChannel(ev.Channel.ID)
 				}
+			case socketmode.EventTypeInteractive:
+				callback, ok := event.Data.(slack.InteractionCallback)
+				if !ok {
+					continue
+				}
+
+				s.socket.Ack(*event.Request)
+
+				if callback.Type == slack.InteractionTypeBlockActions {
+					s.handleBlockActions(&callback)
+				}
+			case socketmode.EventTypeSlashCommand:
+				cmd, ok := event.Data.(slack.SlashCommand)
+				if !ok {
+					continue
+				}
+
+				s.socket.Ack(*event.Request)
+
+				s.handleSlashCommand(&cmd)
+			case socketmode.EventTypeErrorBadMessage:
+				bad, ok := event.Data.(*socketmode.ErrorBadMessage)
+				if !ok {
+					continue
+				}
+
+				s.handleBadMessage(bad)
 			}
 		}
 	}
 }
 
+// handleBadMessage handles the message that socketmode has failed to parse.
+// Events that slackevents does not support, such as user_change, are sent as bad messages.
+func (s *slackService) handleBadMessage(bad *socketmode.ErrorBadMessage) {
+	var req socketmode.Request
+	if err := json.Unmarshal(bad.Message, &req); err != nil || req.Type != socketmode.RequestTypeEventsAPI {
+		s.l.Warn("received bad message", slog.Any("err", bad.Cause))
+		return
+	}
+
+	s.socket.Ack(req)
+
+	u, err := parseUserChangeEvent(req.Payload)
+	if err != nil {
+		s.l.Warn("received bad event", slog.Any("err", err))
+		return
+	}
+	if u != nil {
+		s.directory.setUser(u)
+	}
+}
+
+// send sends the event to the bot, unless the service is closed.
+func (s *slackService) send(event *service.Event) {
+	select {
+	case <-s.done:
+	case s.ch <- event:
+	}
+}
+
 // handleHello handles the hello event.
+// Socket Mode sends the hello event on each connection, including reconnects.
 func (s *slackService) handleHello() {
-	s.ch <- &service.Event{
+	s.connected = true
+	s.outbox.SetConnected(true)
+
+	s.send(&service.Event{
 		Type: service.ConnectedEvent,
 		Data: newHello(s),
+	})
+}
+
+// handleDisconnect handles the disconnect and connection error events.
+// socketmode.Client reconnects automatically after them.
+func (s *slackService) handleDisconnect() {
+	if !s.connected {
+		return
 	}
+	s.connected = false
+	s.outbox.SetConnected(false)
+
+	s.send(&service.Event{
+		Type: service.DisconnectedEvent,
+	})
+}
+
+// userMessageSubTypes are the subtypes of messages posted by users.
+var userMessageSubTypes = map[string]bool{
+	"":                 true,
+	"thread_broadcast": true,
+	"file_share":       true,
+	"me_message":       true,
 }
 
 // handleMessage handles the message event.
 func (s *slackService) handleMessage(msg *slackevents.MessageEvent) {
-	if msg.User == s.UserID() {
-		// bot message
+	switch msg.SubType {
+	case "message_changed":
+		s.handleMessageChanged(msg)
+		return
+	case "message_deleted":
+		s.handleMessageDeleted(msg)
 		return
 	}
 
-	s.ch <- &service.Event{
+	if !userMessageSubTypes[msg.SubType] || msg.BotID != "" || msg.User == s.UserID() {
+		// bot message, or system message such as channel_join
+		return
+	}
+
+	s.send(&service.Event{
 		Type: service.MessageEvent,
 		Data: newMessage(s, msg),
+	})
+}
+
+// handleMessageChanged handles the message event of the message_changed subtype.
+func (s *slackService) handleMessageChanged(msg *slackevents.MessageEvent) {
+	if msg.Message == nil {
+		return
+	}
+	edited := *msg.Message
+	edited.Channel = msg.Channel
+	edited.ChannelType = msg.ChannelType
+
+	if !userMessageSubTypes[edited.SubType] || edited.BotID != "" || edited.User == s.UserID() {
+		return
+	}
+	if msg.PreviousMessage != nil && msg.PreviousMessage.Text == edited.Text {
+		// attachments such as link previews are updated
+		return
+	}
+
+	s.send(&service.Event{
+		Type: service.MessageEditedEvent,
+		Data: newMessage(s, &edited),
+	})
+}
+
+// handleMessageDeleted handles the message event of the message_deleted subtype.
+func (s *slackService) handleMessageDeleted(msg *slackevents.MessageEvent) {
+	prev := msg.PreviousMessage
+	if prev == nil || prev.BotID != "" || prev.User == s.UserID() {
+		return
+	}
+
+	s.send(&service.Event{
+		Type: service.MessageDeletedEvent,
+		Data: &plugin.MessageRef{
+			ChannelID: msg.Channel,
+			MessageID: prev.TimeStamp,
+		},
+	})
+}
+
+// handleReaction handles the reaction_added and reaction_removed events.
+func (s *slackService) handleReaction(userID, emoji string, item slackevents.Item, added bool) {
+	if userID == s.UserID() || item.Type != "message" {
+		// bot reaction, or reaction to a file
+		return
+	}
+
+	s.send(&service.Event{
+		Type: service.ReactionEvent,
+		Data: &reaction{
+			userID: userID,
+			emoji:  emoji,
+			target: plugin.MessageRef{
+				ChannelID: item.Channel,
+				MessageID: item.Timestamp,
+			},
+			added: added,
+		},
+	})
+}
+
+// handleSlashCommand handles the slash command as a message posted by the user.
+func (s *slackService) handleSlashCommand(cmd *slack.SlashCommand) {
+	s.send(&service.Event{
+		Type: service.MessageEvent,
+		Data: newSlashCommandMessage(s, cmd),
+	})
+}
+
+// pluginCommand returns the plugin command of the slash command.
+func (s *slackService) pluginCommand(slashCommand string) string {
+	if command, ok := s.slashCommands[slashCommand]; ok {
+		return command
+	}
+
+	return strings.TrimPrefix(slashCommand, "/")
+}
+
+// handleBlockActions handles the block_actions interaction, that is sent when
+// a user clicks a button or selects an option in a message.
+func (s *slackService) handleBlockActions(callback *slack.InteractionCallback) {
+	for _, action := range callback.ActionCallback.BlockActions {
+		value := action.Value
+		if action.Type == slack.ActionType(slack.OptTypeStatic) {
+			value = action.SelectedOption.Value
+		}
+
+		s.send(&service.Event{
+			Type: service.InteractionEvent,
+			Data: &interaction{
+				service:  s,
+				userID:   callback.User.ID,
+				actionID: action.ActionID,
+				value:    value,
+				target: plugin.MessageRef{
+					ChannelID: callback.Container.ChannelID,
+					MessageID: callback.Container.MessageTs,
+				},
+				threadID: callback.Container.ThreadTs,
+			},
+		})
 	}
 }
 
@@ -169,7 +456,7 @@ func (s *slackService) handleMessage(msg *slackevents.MessageEvent) {
 func (s *slackService) Close() error {
 	s.exit()
 	s.wg.Wait()
-	return nil
+	return s.outbox.Close()
 }
 
 // UserID implements the service.Service interface.
@@ -179,22 +466,248 @@ func (s *slackService) UserID() string {
 
 // Post implements the service.Service interface.
 func (s *slackService) Post(channelID, text string) {
-	s.PostToThread(channelID, text, "")
+	s.PostToThread(channelID, "", text)
 }
 
-// Post implements the service.Service interface.
-func (s *slackService) PostToThread(channelID, text string, ts string) {
-	s.client.PostMessage(channelID, slack.MsgOptionText(text, false), slack.MsgOptionTS(ts))
+// DirectMessage implements the service.DirectMessenger interface.
+func (s *slackService) DirectMessage(userID, text string) error {
+	ch, _, _, err := s.client.OpenConversation(&slack.OpenConversationParameters{
+		Users: []string{userID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open the direct message channel: %w", err)
+	}
+
+	s.Post(ch.ID, text)
+	return nil
+}
+
+// isDirectChannel returns whether the channel is a direct message channel.
+// IDs of direct message channels start with "D".
+func isDirectChannel(channelID string) bool {
+	return strings.HasPrefix(channelID, "D")
+}
+
+// PostContext implements the service.ContextPoster interface.
+func (s *slackService) PostContext(ctx context.Context, channelID, text string) (plugin.MessageRef, error) {
+	return s.PostToThreadContext(ctx, channelID, "", text)
+}
+
+// PostToThread implements the service.ThreadPoster interface.
+// threadID is the timestamp of the parent message.
+func (s *slackService) PostToThread(channelID, threadID, text string) {
+	s.postToThread(context.Background(), channelID, threadID, text)
+}
+
+// PostToThreadContext posts a new message to the thread of the channel, and waits until it is delivered.
+func (s *slackService) PostToThreadContext(ctx context.Context, channelID, threadID, text string) (plugin.MessageRef, error) {
+	return s.postToThread(ctx, channelID, threadID, text).Wait(ctx)
+}
+
+// postToThread pushes the text to the outbox, split into messages if it is too long.
+// The returned *outbox.Delivery reports the result of the first message.
+func (s *slackService) postToThread(ctx context.Context, channelID, threadID, text string) *outbox.Delivery {
+	var delivery *outbox.Delivery
+	for _, chunk := range s.splitText(text) {
+		d := s.outbox.Push(ctx, &outbox.Message{
+			ChannelID: channelID,
+			ThreadID:  threadID,
+			Text:      chunk,
+		})
+		if delivery == nil {
+			delivery = d
+		}
+	}
+
+	return delivery
+}
+
+// maxMessageLength is the maximum length of the text of a message.
+// Slack truncates messages longer than it.
+const maxMessageLength = 40000
+
+// splitText splits the text to fit in messages.
+// Returns the text as it is if it needs more than maxSplitMessages messages,
+// so that it is uploaded as a file when it is delivered.
+func (s *slackService) splitText(text string) []string {
+	chunks := split.Text(text, maxMessageLength)
+	if s.maxSplitMessages > 0 && len(chunks) > s.maxSplitMessages {
+		return []string{text}
+	}
+
+	return chunks
+}
+
+// PostRich implements the service.RichPoster interface.
+func (s *slackService) PostRich(channelID string, msg *rich.Message) {
+	s.PostRichToThread(channelID, "", msg)
+}
+
+// PostRichContext implements the service.RichPoster interface.
+func (s *slackService) PostRichContext(ctx context.Context, channelID string, msg *rich.Message) (plugin.MessageRef, error) {
+	return s.postRichToThread(ctx, channelID, "", msg).Wait(ctx)
+}
+
+// PostRichToThread posts a new rich message to the thread of the channel.
+func (s *slackService) PostRichToThread(channelID, threadID string, msg *rich.Message) {
+	s.postRichToThread(context.Background(), channelID, threadID, msg)
+}
+
+func (s *slackService) postRichToThread(ctx context.Context, channelID, threadID string, msg *rich.Message) *outbox.Delivery {
+	return s.outbox.Push(ctx, &outbox.Message{
+		ChannelID: channelID,
+		ThreadID:  threadID,
+		Text:      msg.PlainText(),
+		Rich:      msg,
+	})
+}
+
+// PostEphemeral posts a new message to the thread of the channel, that is visible only to the user.
+// Ephemeral messages are not queued to the outbox, because they are meaningless after reconnecting.
+func (s *slackService) PostEphemeral(channelID, threadID, userID, text string) {
+	for _, chunk := range split.Text(text, maxMessageLength) {
+		_, err := s.client.PostEphemeral(channelID, userID,
+			slack.MsgOptionText(chunk, false),
+			slack.MsgOptionTS(threadID))
+		if err != nil {
+			s.l.Error("Failed to post an ephemeral message", slog.String("channel_id", channelID), slog.Any("err", err))
+			return
+		}
+	}
+}
+
+// deliver sends the message of the outbox to Slack.
+func (s *slackService) deliver(ctx context.Context, msg *outbox.Message) (plugin.MessageRef, error) {
+	if msg.Rich == nil && utf8.RuneCountInString(msg.Text) > maxMessageLength {
+		return s.uploadText(ctx, msg)
+	}
+
+	opts := []slack.MsgOption{
+		slack.MsgOptionText(msg.Text, false),
+		slack.MsgOptionTS(msg.ThreadID),
+	}
+	if msg.Rich != nil && len(msg.Rich.Blocks) > 0 {
+		// the text is used for notifications
+		opts[0] = slack.MsgOptionText(msg.Rich.Text, false)
+		opts = append(opts, slack.MsgOptionBlocks(renderBlocks(msg.Rich)...))
+	}
+
+	channelID, ts, err := s.client.PostMessageContext(ctx, msg.ChannelID, opts...)
+	if err != nil {
+		return plugin.MessageRef{}, classifyError(err)
+	}
+
+	return plugin.MessageRef{
+		ChannelID: channelID,
+		MessageID: ts,
+	}, nil
+}
+
+// uploadText uploads the text of the message as a file, because it is too long to be posted.
+// The timestamp of the message sharing the file is not available,
+// so the returned reference does not have the message ID.
+func (s *slackService) uploadText(ctx context.Context, msg *outbox.Message) (plugin.MessageRef, error) {
+	text := split.Unfence(msg.Text)
+	_, err := s.client.UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+		Content:         text,
+		FileSize:        len(text),
+		Filename:        "message.txt",
+		Channel:         msg.ChannelID,
+		ThreadTimestamp: msg.ThreadID,
+	})
+	if err != nil {
+		return plugin.MessageRef{}, classifyError(err)
+	}
+
+	return plugin.MessageRef{
+		ChannelID: msg.ChannelID,
+	}, nil
+}
+
+// transientErrors are Slack API errors that may be fixed by retrying.
+var transientErrors = map[string]bool{
+	"internal_error":      true,
+	"fatal_error":         true,
+	"service_unavailable": true,
+	"request_timeout":     true,
+}
+
+// classifyError converts err of the Slack API for the outbox.
+func classifyError(err error) error {
+	var (
+		rateLimited *slack.RateLimitedError
+		apiErr      slack.SlackErrorResponse
+	)
+	switch {
+	case errors.As(err, &rateLimited):
+		return &outbox.RateLimitError{RetryAfter: rateLimited.RetryAfter, Err: err}
+	case errors.As(err, &apiErr) && !transientErrors[apiErr.Err]:
+		return outbox.Permanent(err)
+	}
+
+	return err
+}
+
+// handleDeliveryFailure handles the message that the outbox has given up delivering.
+func (s *slackService) handleDeliveryFailure(ctx context.Context, msg *outbox.Message, err error) {
+	select {
+	case <-ctx.Done():
+	case s.ch <- &service.Event{
+		Type: service.DeliveryFailedEvent,
+		Data: &plugin.DeliveryFailure{
+			ChannelID: msg.ChannelID,
+			Text:      msg.Text,
+			Err:       err,
+		},
+	}:
+	}
+}
+
+// Upload implements the service.Uploader interface.
+func (s *slackService) Upload(channelID, name string, r io.Reader, comment string) error {
+	// files.uploadV2 requires the size of the file in advance
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read the file: %w", err)
+	}
+
+	_, err = s.client.UploadFileV2Context(context.Background(), slack.UploadFileV2Parameters{
+		Reader:         bytes.NewReader(data),
+		FileSize:       len(data),
+		Filename:       name,
+		InitialComment: comment,
+		Channel:        channelID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload the file: %w", err)
+	}
+
+	return nil
 }
 
 // Mention implements the service.Service interface.
 func (s *slackService) Mention(channelID, userID, text string) {
-	s.MentionToThread(channelID, userID, text, "")
+	s.MentionToThread(channelID, "", userID, text)
 }
 
-// Mention implements the service.Service interface.
-func (s *slackService) MentionToThread(channelID, userID, text string, ts string) {
-	s.PostToThread(channelID, msgfmt.Format(
+// MentionContext implements the service.ContextPoster interface.
+func (s *slackService) MentionContext(ctx context.Context, channelID, userID, text string) (plugin.MessageRef, error) {
+	return s.MentionToThreadContext(ctx, channelID, "", userID, text)
+}
+
+// MentionToThread posts a new message that mentions to the user to the thread of the channel.
+func (s *slackService) MentionToThread(channelID, threadID, userID, text string) {
+	s.PostToThread(channelID, threadID, mentionText(userID, text))
+}
+
+// MentionToThreadContext posts a new message that mentions to the user to the thread of the channel,
+// and waits until it is delivered.
+func (s *slackService) MentionToThreadContext(ctx context.Context, channelID, threadID, userID, text string) (plugin.MessageRef, error) {
+	return s.PostToThreadContext(ctx, channelID, threadID, mentionText(userID, text))
+}
+
+func mentionText(userID, text string) string {
+	return msgfmt.Format(
 		&msgfmt.Block{
 			Type:    msgfmt.UserBlock,
 			Content: userID,
@@ -204,17 +717,80 @@ func (s *slackService) MentionToThread(channelID, userID, text string, ts string
 			Type:    msgfmt.TextBlock,
 			Content: text,
 		},
-	), ts)
+	)
+}
+
+// Edit implements the service.Editor interface.
+func (s *slackService) Edit(ref plugin.MessageRef, text string) error {
+	_, _, _, err := s.client.UpdateMessage(ref.ChannelID, ref.MessageID, slack.MsgOptionText(text, false))
+	if err != nil {
+		return fmt.Errorf("failed to update the message: %w", err)
+	}
+
+	return nil
+}
+
+// updateRich replaces the message with the rich message.
+func (s *slackService) updateRich(ref plugin.MessageRef, msg *rich.Message) error {
+	// blocks are always set to remove the blocks of the message
+	_, _, _, err := s.client.UpdateMessage(ref.ChannelID, ref.MessageID,
+		slack.MsgOptionText(msg.Text, false),
+		slack.MsgOptionBlocks(renderBlocks(msg)...))
+	if err != nil {
+		return fmt.Errorf("failed to update the message: %w", err)
+	}
+
+	return nil
+}
+
+// Delete implements the service.Editor interface.
+func (s *slackService) Delete(ref plugin.MessageRef) error {
+	_, _, err := s.client.DeleteMessage(ref.ChannelID, ref.MessageID)
+	if err != nil {
+		return fmt.Errorf("failed to delete the message: %w", err)
+	}
+
+	return nil
+}
+
+// React implements the service.Reactor interface.
+// emoji is the name of the emoji, with or without colons.
+func (s *slackService) React(ref plugin.MessageRef, emoji string) error {
+	err := s.client.AddReaction(strings.Trim(emoji, ":"), slack.NewRefToMessage(ref.ChannelID, ref.MessageID))
+	if err != nil {
+		return fmt.Errorf("failed to add the reaction: %w", err)
+	}
+
+	return nil
 }
 
 // ProcessCommmand processes the specified command on the channel.
 func (s *slackService) ProcessCommand(channelID string, command string) {
-	go func() {
-		s.ch <- &service.Event{
-			Type: service.MessageEvent,
-			Data: newCommandMessage(s, channelID, command),
-		}
-	}()
+	go s.ProcessCommandContext(context.Background(), channelID, command)
+}
+
+// ProcessCommandContext processes the specified command on the channel.
+func (s *slackService) ProcessCommandContext(ctx context.Context, channelID string, command string) error {
+	return s.processCommand(ctx, channelID, "", command)
+}
+
+// ProcessThreadCommand processes the specified command on the thread of the channel.
+func (s *slackService) ProcessThreadCommand(channelID, threadID, command string) {
+	go s.processCommand(context.Background(), channelID, threadID, command)
+}
+
+func (s *slackService) processCommand(ctx context.Context, channelID, threadID, command string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.done:
+		return service.ErrClosed
+	case s.ch <- &service.Event{
+		Type: service.MessageEvent,
+		Data: newCommandMessage(s, channelID, threadID, command),
+	}:
+		return nil
+	}
 }
 
 // Channel returns a channel of specified channelID.
@@ -224,9 +800,7 @@ func (s *slackService) Channel(channelID string) plugin.Channel {
 		return nil
 	}
 
-	ch, err := s.client.GetConversationInfo(&slack.GetConversationInfoInput{
-		ChannelID: channelID,
-	})
+	ch, err := s.directory.channel(context.Background(), channelID)
 	if err != nil {
 		s.l.Error("Failed to get channel info", slog.String("channel_id", channelID), slog.Any("err", err))
 		return nil
@@ -245,7 +819,7 @@ func (s *slackService) User(userID string) plugin.User {
 		return nil
 	}
 
-	u, err := s.client.GetUserInfo(userID)
+	u, err := s.directory.user(context.Background(), userID)
 	if err != nil {
 		s.l.Error("Failed to get user info", slog.String("user_id", userID), slog.Any("err", err))
 		return nil
@@ -256,9 +830,16 @@ func (s *slackService) User(userID string) plugin.User {
 		name:        u.Name,
 		fullName:    u.RealName,
 		displayName: u.Profile.DisplayName,
+		avatarURL:   u.Profile.Image192,
+		bot:         u.IsBot,
 	}
 }
 
+// SetEphemeralHelp implements the service.EphemeralHelper interface.
+func (s *slackService) SetEphemeralHelp(ephemeral bool) {
+	s.ephemeralHelp = ephemeral
+}
+
 // EscapeHelp implements the service.Service interface.
 func (s *slackService) EscapeHelp(help string) string {
 	escaped := bytes.NewBuffer(make([]byte, len(help)+8))
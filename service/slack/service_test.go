@@ -0,0 +1,61 @@
+package slack
+
+import (
+	"testing"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/service"
+	"github.com/slack-go/slack/slackevents"
+)
+
+var handleMessageChangedTests = map[string]struct {
+	channelID   string
+	channelType string
+	direct      bool
+}{
+	"channel": {channelID: "C1", channelType: "channel", direct: false},
+	"direct":  {channelID: "D1", channelType: "im", direct: true},
+}
+
+func Test_slackService_handleMessageChanged(t *testing.T) {
+	for name, tt := range handleMessageChangedTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &slackService{
+				userID: "UBOT",
+				ch:     make(chan *service.Event, 1),
+			}
+
+			// the inner message of message_changed events does not have the channel
+			s.handleMessageChanged(&slackevents.MessageEvent{
+				Channel:     tt.channelID,
+				ChannelType: tt.channelType,
+				SubType:     "message_changed",
+				Message: &slackevents.MessageEvent{
+					User:      "U1",
+					Text:      "edited",
+					TimeStamp: "1700000000.000100",
+				},
+				PreviousMessage: &slackevents.MessageEvent{
+					User:      "U1",
+					Text:      "original",
+					TimeStamp: "1700000000.000100",
+				},
+			})
+
+			e := <-s.ch
+			if e.Type != service.MessageEditedEvent {
+				t.Fatalf("event type => %v, want %v", e.Type, service.MessageEditedEvent)
+			}
+			msg := e.Data.(plugin.Message)
+			if got := msg.ChannelID(); got != tt.channelID {
+				t.Errorf("ChannelID() => %q, want %q", got, tt.channelID)
+			}
+			if got := msg.(plugin.DirectChecker).IsDirect(); got != tt.direct {
+				t.Errorf("IsDirect() => %v, want %v", got, tt.direct)
+			}
+		})
+	}
+}
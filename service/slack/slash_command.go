@@ -0,0 +1,162 @@
+package slack
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+	"github.com/kechako/gopher-bot/v2/service/internal/split"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// slashCommandMessage is a plugin.Message of a slash command.
+// Messages posted in reply to it are sent to the response URL of the command,
+// so that they can be posted to channels that the bot has not joined.
+type slashCommandMessage struct {
+	*message
+	service     *slackService
+	responseURL string
+}
+
+var (
+	_ plugin.Message          = (*slashCommandMessage)(nil)
+	_ plugin.ContextReplier   = (*slashCommandMessage)(nil)
+	_ plugin.ThreadReplier    = (*slashCommandMessage)(nil)
+	_ plugin.EphemeralReplier = (*slashCommandMessage)(nil)
+	_ plugin.RichReplier      = (*slashCommandMessage)(nil)
+)
+
+// newSlashCommandMessage returns a new *slashCommandMessage as plugin.Message.
+// The text of the message is the plugin command of the slash command followed by the text.
+func newSlashCommandMessage(service *slackService, cmd *slack.SlashCommand) plugin.Message {
+	text := strings.TrimSpace(service.pluginCommand(cmd.Command) + " " + cmd.Text)
+
+	channelType := "channel"
+	if isDirectChannel(cmd.ChannelID) {
+		channelType = "im"
+	}
+
+	return &slashCommandMessage{
+		message: newMessage(service, &slackevents.MessageEvent{
+			Channel:     cmd.ChannelID,
+			ChannelType: channelType,
+			User:        cmd.UserID,
+			Text:        text,
+		}),
+		service:     service,
+		responseURL: cmd.ResponseURL,
+	}
+}
+
+// Post implements the plugin.Message interface.
+func (m *slashCommandMessage) Post(text string) {
+	for _, chunk := range split.Text(text, maxMessageLength) {
+		m.respond(&slack.WebhookMessage{
+			Text:         chunk,
+			ResponseType: slack.ResponseTypeInChannel,
+		}, func() {
+			m.message.Post(chunk)
+		})
+	}
+}
+
+// Mention implements the plugin.Message interface.
+func (m *slashCommandMessage) Mention(text string) {
+	m.Post(mentionText(m.UserID(), text))
+}
+
+// PostContext implements the plugin.ContextReplier interface.
+// The response URL does not return the timestamp of the message,
+// so the returned reference does not have the message ID unless the message is posted instead.
+func (m *slashCommandMessage) PostContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	var ref plugin.MessageRef
+	for i, chunk := range split.Text(text, maxMessageLength) {
+		r, err := m.respondContext(ctx, &slack.WebhookMessage{
+			Text:         chunk,
+			ResponseType: slack.ResponseTypeInChannel,
+		}, func(ctx context.Context) (plugin.MessageRef, error) {
+			return m.message.PostContext(ctx, chunk)
+		})
+		if err != nil {
+			return plugin.MessageRef{}, err
+		}
+		if i == 0 {
+			ref = r
+		}
+	}
+
+	return ref, nil
+}
+
+// MentionContext implements the plugin.ContextReplier interface.
+func (m *slashCommandMessage) MentionContext(ctx context.Context, text string) (plugin.MessageRef, error) {
+	return m.PostContext(ctx, mentionText(m.UserID(), text))
+}
+
+// ReplyInThread implements the plugin.ThreadReplier interface.
+// Slash commands do not have messages to start threads from, so the message is posted.
+func (m *slashCommandMessage) ReplyInThread(text string) {
+	m.Post(text)
+}
+
+// PostToChannel implements the plugin.ThreadReplier interface.
+func (m *slashCommandMessage) PostToChannel(text string) {
+	m.Post(text)
+}
+
+// PostEphemeral implements the plugin.EphemeralReplier interface.
+func (m *slashCommandMessage) PostEphemeral(text string) {
+	for _, chunk := range split.Text(text, maxMessageLength) {
+		m.respond(&slack.WebhookMessage{
+			Text:         chunk,
+			ResponseType: slack.ResponseTypeEphemeral,
+		}, func() {
+			m.message.PostEphemeral(chunk)
+		})
+	}
+}
+
+// PostRich implements the plugin.RichReplier interface.
+func (m *slashCommandMessage) PostRich(msg *rich.Message) {
+	m.respond(&slack.WebhookMessage{
+		Text:         msg.Text,
+		Blocks:       &slack.Blocks{BlockSet: renderBlocks(msg)},
+		ResponseType: slack.ResponseTypeInChannel,
+	}, func() {
+		m.message.PostRich(msg)
+	})
+}
+
+// PostHelp implements the plugin.Message interface.
+// Help messages are visible only to the user.
+func (m *slashCommandMessage) PostHelp(help *plugin.Help) {
+	m.PostEphemeral(m.service.EscapeHelp(help.String()))
+}
+
+// respond sends the message to the response URL.
+// fallback is called if it fails, e.g. the response URL has expired.
+func (m *slashCommandMessage) respond(msg *slack.WebhookMessage, fallback func()) {
+	m.respondContext(context.Background(), msg, func(ctx context.Context) (plugin.MessageRef, error) {
+		fallback()
+		return plugin.MessageRef{}, nil
+	})
+}
+
+// respondContext is like respond, but returns the reference to the message.
+// fallback is not called if ctx is done.
+func (m *slashCommandMessage) respondContext(ctx context.Context, msg *slack.WebhookMessage, fallback func(ctx context.Context) (plugin.MessageRef, error)) (plugin.MessageRef, error) {
+	err := slack.PostWebhookContext(ctx, m.responseURL, msg)
+	if err == nil {
+		return plugin.MessageRef{ChannelID: m.ChannelID()}, nil
+	}
+	if ctx.Err() != nil {
+		return plugin.MessageRef{}, ctx.Err()
+	}
+
+	m.service.l.Warn("Failed to respond to the slash command, post the message instead",
+		slog.String("channel_id", m.ChannelID()), slog.Any("err", err))
+	return fallback(ctx)
+}
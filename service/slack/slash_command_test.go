@@ -0,0 +1,183 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/service/internal/outbox"
+	"github.com/slack-go/slack"
+)
+
+// recorder records the texts sent to a fake server.
+type recorder struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (r *recorder) record(text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.texts = append(r.texts, text)
+}
+
+func (r *recorder) Texts() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.texts
+}
+
+// newResponseURL returns a fake response URL of slash commands, that responds with status.
+func newResponseURL(t *testing.T, status int, r *recorder) string {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var msg slack.WebhookMessage
+		if err := json.NewDecoder(req.Body).Decode(&msg); err != nil {
+			t.Error("failed to decode the webhook message: ", err)
+		}
+		r.record(msg.ResponseType + ": " + msg.Text)
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv.URL
+}
+
+// newTestService returns a *slackService that posts messages to a fake Web API.
+func newTestService(t *testing.T, r *recorder) *slackService {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/chat.postMessage" {
+			t.Errorf("unexpected API call: %s", req.URL.Path)
+			http.NotFound(w, req)
+			return
+		}
+		r.record(req.FormValue("channel") + ": " + req.FormValue("text"))
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"ok":true,"channel":"C1","ts":"1700000000.000100"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	l := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := &slackService{
+		client: slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/")),
+		l:      l,
+	}
+	s.outbox = outbox.New(s.deliver, &outbox.Config{Logger: l})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	s.outbox.Start(ctx)
+	s.outbox.SetConnected(true)
+
+	return s
+}
+
+var slashCommandContextTests = map[string]struct {
+	status    int
+	mention   bool
+	ref       plugin.MessageRef
+	responses []string
+	posts     []string
+}{
+	"PostContext": {
+		status:    http.StatusOK,
+		ref:       plugin.MessageRef{ChannelID: "C1"},
+		responses: []string{"in_channel: hello"},
+	},
+	"MentionContext": {
+		status:    http.StatusOK,
+		mention:   true,
+		ref:       plugin.MessageRef{ChannelID: "C1"},
+		responses: []string{"in_channel: " + mentionText("U1", "hello")},
+	},
+	"PostContext expired": {
+		status:    http.StatusNotFound,
+		ref:       plugin.MessageRef{ChannelID: "C1", MessageID: "1700000000.000100"},
+		responses: []string{"in_channel: hello"},
+		posts:     []string{"C1: hello"},
+	},
+	"MentionContext expired": {
+		status:    http.StatusNotFound,
+		mention:   true,
+		ref:       plugin.MessageRef{ChannelID: "C1", MessageID: "1700000000.000100"},
+		responses: []string{"in_channel: " + mentionText("U1", "hello")},
+		posts:     []string{"C1: " + mentionText("U1", "hello")},
+	},
+}
+
+func Test_slashCommandMessage_context(t *testing.T) {
+	for name, tt := range slashCommandContextTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var responses, posts recorder
+			s := newTestService(t, &posts)
+			msg := newSlashCommandMessage(s, &slack.SlashCommand{
+				Command:     "/loc",
+				ChannelID:   "C1",
+				UserID:      "U1",
+				ResponseURL: newResponseURL(t, tt.status, &responses),
+			}).(plugin.ContextReplier)
+
+			var ref plugin.MessageRef
+			var err error
+			if tt.mention {
+				ref, err = msg.MentionContext(context.Background(), "hello")
+			} else {
+				ref, err = msg.PostContext(context.Background(), "hello")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(ref, tt.ref); diff != "" {
+				t.Errorf("reference differs: (-got +want)\n%s", diff)
+			}
+			if diff := cmp.Diff(responses.Texts(), tt.responses); diff != "" {
+				t.Errorf("responses differ: (-got +want)\n%s", diff)
+			}
+			if diff := cmp.Diff(posts.Texts(), tt.posts); diff != "" {
+				t.Errorf("posts differ: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_slashCommandMessage_PostContext_canceled(t *testing.T) {
+	t.Parallel()
+
+	var posts recorder
+	s := newTestService(t, &posts)
+	msg := newSlashCommandMessage(s, &slack.SlashCommand{
+		ChannelID:   "C1",
+		UserID:      "U1",
+		ResponseURL: newResponseURL(t, http.StatusOK, &recorder{}),
+	}).(plugin.ContextReplier)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := msg.PostContext(ctx, "hello")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("PostContext() => error %v, want %v", err, context.Canceled)
+	}
+	if texts := posts.Texts(); len(texts) > 0 {
+		t.Errorf("the message must not be posted instead: %q", texts)
+	}
+}
@@ -1,12 +1,21 @@
 package slack
 
+import "github.com/kechako/gopher-bot/v2/plugin"
+
 type user struct {
 	id          string
 	name        string
 	fullName    string
 	displayName string
+	avatarURL   string
+	bot         bool
 }
 
+var (
+	_ plugin.User    = (*user)(nil)
+	_ plugin.Profile = (*user)(nil)
+)
+
 // ID implements the plugin.User interface.
 func (u *user) ID() string {
 	return u.id
@@ -32,3 +41,13 @@ func (u *user) DisplayName() string {
 	}
 	return u.displayName
 }
+
+// AvatarURL implements the plugin.Profile interface.
+func (u *user) AvatarURL() string {
+	return u.avatarURL
+}
+
+// IsBot implements the plugin.Profile interface.
+func (u *user) IsBot() bool {
+	return u.bot
+}
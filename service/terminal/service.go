@@ -0,0 +1,530 @@
+// Package terminal provides a bot service that reads messages from a terminal.
+// It is useful to run a bot locally without any chat service.
+//
+// Each line read from the input is handled as a message posted by the current user
+// to the current channel. Lines starting with "/" are meta-commands:
+//
+//	/channel <channel>     switches the current channel
+//	/user <user>           switches the current user
+//	/react <id> <emoji>    adds a reaction of the current user to the message
+//	/unreact <id> <emoji>  removes a reaction of the current user from the message
+//	/click <id> <action> [value]
+//	                       clicks a button or selects an option of the message
+//
+// The IDs of the messages posted by the bot are numbered from 1.
+//
+// Each line is handled after the bot has finished handling the previous one,
+// and the service stops at the end of the input, so a script can be piped to the bot.
+//
+// Mentions are written as @<user>, and direct message channels with users
+// are written as @<user> too, e.g. "/channel @alice".
+package terminal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kechako/gopher-bot/v2/internal/local"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/plugin/rich"
+	"github.com/kechako/gopher-bot/v2/service"
+)
+
+const (
+	defaultBotUserID = "gopher-bot"
+	defaultUserID    = "user"
+	defaultChannelID = "general"
+)
+
+type Config struct {
+	Logger *slog.Logger
+	// In is the input to read messages. The default is os.Stdin.
+	In io.Reader
+	// Out is the output to write messages posted by the bot. The default is os.Stdout.
+	Out io.Writer
+	// BotUserID is the user ID of the bot. The default is "gopher-bot".
+	BotUserID string
+	// UserID is the initial user ID that posts messages. The default is "user".
+	UserID string
+	// ChannelID is the initial channel ID that messages are posted to. The default is "general".
+	ChannelID string
+}
+
+func (cfg *Config) logger() *slog.Logger {
+	var l *slog.Logger
+	if cfg != nil {
+		l = cfg.Logger
+	}
+	if l != nil {
+		return l
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, nil))
+}
+
+// terminalService represents a service for a terminal.
+type terminalService struct {
+	in     io.Reader
+	out    io.Writer
+	outMux sync.Mutex
+	// lastMessageID is the ID of the last message posted by the bot.
+	lastMessageID int
+
+	botUserID string
+	userID    string
+	channelID string
+	mux       sync.Mutex
+
+	ephemeralHelp bool
+
+	l *slog.Logger
+
+	ch chan *service.Event
+	// closed indicates ch is closed at the end of the input.
+	closed bool
+	chMux  sync.RWMutex
+
+	wg   sync.WaitGroup
+	done <-chan struct{}
+	exit context.CancelFunc
+}
+
+var (
+	_ local.Service           = (*terminalService)(nil)
+	_ service.ContextPoster   = (*terminalService)(nil)
+	_ service.Editor          = (*terminalService)(nil)
+	_ service.Reactor         = (*terminalService)(nil)
+	_ service.ThreadPoster    = (*terminalService)(nil)
+	_ service.DirectMessenger = (*terminalService)(nil)
+	_ service.RichPoster      = (*terminalService)(nil)
+	_ service.Uploader        = (*terminalService)(nil)
+	_ service.EphemeralHelper = (*terminalService)(nil)
+)
+
+// New returns a new terminal service as service.Service.
+func New(cfg *Config) (service.Service, error) {
+	s := &terminalService{
+		in:        os.Stdin,
+		out:       os.Stdout,
+		botUserID: defaultBotUserID,
+		userID:    defaultUserID,
+		channelID: defaultChannelID,
+		l:         cfg.logger(),
+	}
+
+	if cfg != nil {
+		if cfg.In != nil {
+			s.in = cfg.In
+		}
+		if cfg.Out != nil {
+			s.out = cfg.Out
+		}
+		if cfg.BotUserID != "" {
+			s.botUserID = cfg.BotUserID
+		}
+		if cfg.UserID != "" {
+			s.userID = cfg.UserID
+		}
+		if cfg.ChannelID != "" {
+			s.channelID = cfg.ChannelID
+		}
+	}
+
+	return s, nil
+}
+
+// Start implements the service.Service interface.
+func (s *terminalService) Start(ctx context.Context) (<-chan *service.Event, error) {
+	s.l.Info("Start terminal bot service")
+
+	s.ch = make(chan *service.Event)
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.done = ctx.Done()
+	s.exit = cancel
+
+	lines := make(chan string)
+	// reading the input cannot be canceled, so the goroutine is not waited on Close.
+	go s.read(ctx, lines)
+
+	s.wg.Add(1)
+	go s.loop(ctx, lines)
+
+	return s.ch, nil
+}
+
+func (s *terminalService) read(ctx context.Context, lines chan<- string) {
+	defer close(lines)
+
+	scanner := bufio.NewScanner(s.in)
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return
+		case lines <- scanner.Text():
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		s.l.Error("failed to read input", slog.Any("err", err))
+	}
+}
+
+func (s *terminalService) loop(ctx context.Context, lines <-chan string) {
+	defer s.wg.Done()
+
+	s.sendAndWait(ctx, &service.Event{
+		Type: service.ConnectedEvent,
+		Data: local.NewHello(s, s.l),
+	})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case line, ok := <-lines:
+			if !ok {
+				s.l.Info("input is closed")
+				s.closeEvents()
+				return
+			}
+			s.handleLine(ctx, line)
+		}
+	}
+}
+
+// closeEvents closes the event channel to stop the bot.
+func (s *terminalService) closeEvents() {
+	// unblock senders before closing
+	s.exit()
+
+	s.chMux.Lock()
+	defer s.chMux.Unlock()
+
+	s.closed = true
+	close(s.ch)
+}
+
+// handleLine handles a line of the input.
+func (s *terminalService) handleLine(ctx context.Context, line string) {
+	text := strings.TrimSpace(line)
+	if text == "" {
+		return
+	}
+
+	if strings.HasPrefix(text, "/") {
+		s.handleMetaCommand(ctx, text)
+		return
+	}
+
+	s.mux.Lock()
+	userID, channelID := s.userID, s.channelID
+	s.mux.Unlock()
+
+	s.sendAndWait(ctx, &service.Event{
+		Type: service.MessageEvent,
+		Data: local.NewMessage(s, channelID, "", userID, text),
+	})
+}
+
+// handleMetaCommand handles a meta-command.
+func (s *terminalService) handleMetaCommand(ctx context.Context, text string) {
+	fields := strings.Fields(text)
+
+	if (fields[0] == "/react" || fields[0] == "/unreact") && len(fields) == 3 {
+		s.react(ctx, fields[1], fields[2], fields[0] == "/react")
+		return
+	}
+	if fields[0] == "/click" && (len(fields) == 3 || len(fields) == 4) {
+		var value string
+		if len(fields) == 4 {
+			value = fields[3]
+		}
+		s.click(ctx, fields[1], fields[2], value)
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	switch {
+	case fields[0] == "/channel" && len(fields) == 2:
+		s.channelID = strings.TrimPrefix(fields[1], "#")
+		s.println(fmt.Sprintf("* channel is changed to #%s", s.channelID))
+	case fields[0] == "/user" && len(fields) == 2:
+		s.userID = strings.TrimPrefix(fields[1], "@")
+		s.println(fmt.Sprintf("* user is changed to @%s", s.userID))
+	default:
+		s.println("* usage: /channel <channel>, /user <user>, /react <id> <emoji>, /unreact <id> <emoji>, /click <id> <action> [value]")
+	}
+}
+
+// react sends a reaction of the current user to the message in the current channel.
+func (s *terminalService) react(ctx context.Context, messageID, emoji string, added bool) {
+	s.mux.Lock()
+	userID, channelID := s.userID, s.channelID
+	s.mux.Unlock()
+
+	s.sendAndWait(ctx, &service.Event{
+		Type: service.ReactionEvent,
+		Data: local.NewReaction(userID, emoji, plugin.MessageRef{
+			ChannelID: channelID,
+			MessageID: messageID,
+		}, added),
+	})
+}
+
+// click sends an interaction of the current user with the message in the current channel.
+func (s *terminalService) click(ctx context.Context, messageID, actionID, value string) {
+	s.mux.Lock()
+	userID, channelID := s.userID, s.channelID
+	s.mux.Unlock()
+
+	s.sendAndWait(ctx, &service.Event{
+		Type: service.InteractionEvent,
+		Data: local.NewInteraction(s, userID, actionID, value, plugin.MessageRef{
+			ChannelID: channelID,
+			MessageID: messageID,
+		}),
+	})
+}
+
+// send sends the event to the bot.
+// Returns service.ErrClosed if the service is closed.
+func (s *terminalService) send(ctx context.Context, event *service.Event) error {
+	s.chMux.RLock()
+	defer s.chMux.RUnlock()
+
+	if s.closed {
+		return service.ErrClosed
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.done:
+		return service.ErrClosed
+	case s.ch <- event:
+		return nil
+	}
+}
+
+// sendAndWait sends the event to the bot, and waits until the bot has finished handling it,
+// so that the replies are written before the next line is read.
+func (s *terminalService) sendAndWait(ctx context.Context, event *service.Event) {
+	handled := make(chan struct{})
+	event.Done = func() { close(handled) }
+
+	if err := s.send(ctx, event); err != nil {
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-handled:
+	}
+}
+
+func (s *terminalService) println(text string) {
+	s.outMux.Lock()
+	defer s.outMux.Unlock()
+
+	fmt.Fprintln(s.out, text)
+}
+
+// Close implements the service.Service interface.
+// It can be called before Start.
+func (s *terminalService) Close() error {
+	if s.exit != nil {
+		s.exit()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// UserID implements the service.Service interface.
+func (s *terminalService) UserID() string {
+	return s.botUserID
+}
+
+// Post implements the service.Service interface.
+func (s *terminalService) Post(channelID, text string) {
+	s.post(channelID, "", text)
+}
+
+// PostToThread implements the service.ThreadPoster interface.
+func (s *terminalService) PostToThread(channelID, threadID, text string) {
+	s.post(channelID, threadID, text)
+}
+
+// PostToThreadContext posts a new message to the thread of the channel.
+func (s *terminalService) PostToThreadContext(ctx context.Context, channelID, threadID, text string) (plugin.MessageRef, error) {
+	if err := ctx.Err(); err != nil {
+		return plugin.MessageRef{}, err
+	}
+
+	return s.post(channelID, threadID, text), nil
+}
+
+// DirectMessage implements the service.DirectMessenger interface.
+func (s *terminalService) DirectMessage(userID, text string) error {
+	s.Post(local.DirectChannel(userID), text)
+	return nil
+}
+
+// PostEphemeral writes the message that is visible only to the user to the output.
+func (s *terminalService) PostEphemeral(channelID, threadID, userID, text string) {
+	s.println(fmt.Sprintf("[#%s] %s (only visible to @%s): %s", channelID, s.botUserID, userID, text))
+}
+
+// PostContext implements the service.ContextPoster interface.
+func (s *terminalService) PostContext(ctx context.Context, channelID, text string) (plugin.MessageRef, error) {
+	if err := ctx.Err(); err != nil {
+		return plugin.MessageRef{}, err
+	}
+
+	return s.post(channelID, "", text), nil
+}
+
+// PostRich implements the service.RichPoster interface.
+// Rich messages are written as plain text.
+func (s *terminalService) PostRich(channelID string, msg *rich.Message) {
+	s.post(channelID, "", msg.PlainText())
+}
+
+// PostRichContext implements the service.RichPoster interface.
+func (s *terminalService) PostRichContext(ctx context.Context, channelID string, msg *rich.Message) (plugin.MessageRef, error) {
+	return s.PostContext(ctx, channelID, msg.PlainText())
+}
+
+// PostRichToThread posts a new rich message to the thread of the channel as plain text.
+func (s *terminalService) PostRichToThread(channelID, threadID string, msg *rich.Message) {
+	s.post(channelID, threadID, msg.PlainText())
+}
+
+// post writes the message to the output, and returns the reference to the message.
+func (s *terminalService) post(channelID, threadID, text string) plugin.MessageRef {
+	s.outMux.Lock()
+	defer s.outMux.Unlock()
+
+	s.lastMessageID++
+	if threadID == "" {
+		fmt.Fprintf(s.out, "[#%s] %s: %s\n", channelID, s.botUserID, text)
+	} else {
+		fmt.Fprintf(s.out, "[#%s (thread %s)] %s: %s\n", channelID, threadID, s.botUserID, text)
+	}
+
+	return plugin.MessageRef{
+		ChannelID: channelID,
+		MessageID: strconv.Itoa(s.lastMessageID),
+	}
+}
+
+// Mention implements the service.Service interface.
+func (s *terminalService) Mention(channelID, userID, text string) {
+	s.Post(channelID, "@"+userID+" "+text)
+}
+
+// MentionContext implements the service.ContextPoster interface.
+func (s *terminalService) MentionContext(ctx context.Context, channelID, userID, text string) (plugin.MessageRef, error) {
+	return s.PostContext(ctx, channelID, "@"+userID+" "+text)
+}
+
+// MentionToThread posts a new message that mentions to the user to the thread of the channel.
+func (s *terminalService) MentionToThread(channelID, threadID, userID, text string) {
+	s.PostToThread(channelID, threadID, "@"+userID+" "+text)
+}
+
+// MentionToThreadContext posts a new message that mentions to the user to the thread of the channel.
+func (s *terminalService) MentionToThreadContext(ctx context.Context, channelID, threadID, userID, text string) (plugin.MessageRef, error) {
+	return s.PostToThreadContext(ctx, channelID, threadID, "@"+userID+" "+text)
+}
+
+// Edit implements the service.Editor interface.
+func (s *terminalService) Edit(ref plugin.MessageRef, text string) error {
+	s.println(fmt.Sprintf("[#%s] %s (edited %s): %s", ref.ChannelID, s.botUserID, ref.MessageID, text))
+	return nil
+}
+
+// EditRich writes the rich message that replaces the message to the output as plain text.
+func (s *terminalService) EditRich(ref plugin.MessageRef, msg *rich.Message) error {
+	return s.Edit(ref, msg.PlainText())
+}
+
+// Delete implements the service.Editor interface.
+func (s *terminalService) Delete(ref plugin.MessageRef) error {
+	s.println(fmt.Sprintf("[#%s] %s (deleted %s)", ref.ChannelID, s.botUserID, ref.MessageID))
+	return nil
+}
+
+// React implements the service.Reactor interface.
+func (s *terminalService) React(ref plugin.MessageRef, emoji string) error {
+	s.println(fmt.Sprintf("[#%s] %s (reacted %s to %s)", ref.ChannelID, s.botUserID, emoji, ref.MessageID))
+	return nil
+}
+
+// Upload implements the service.Uploader interface.
+// The content of the file is not written, only the name and the size.
+func (s *terminalService) Upload(channelID, name string, r io.Reader, comment string) error {
+	n, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return fmt.Errorf("failed to read the file: %w", err)
+	}
+
+	s.println(fmt.Sprintf("[#%s] %s (uploaded %s, %d bytes): %s", channelID, s.botUserID, name, n, comment))
+	return nil
+}
+
+// ProcessCommmand processes the specified command on the channel.
+func (s *terminalService) ProcessCommand(channelID string, command string) {
+	go s.ProcessCommandContext(context.Background(), channelID, command)
+}
+
+// ProcessCommandContext processes the specified command on the channel.
+func (s *terminalService) ProcessCommandContext(ctx context.Context, channelID string, command string) error {
+	return s.processCommand(ctx, channelID, "", command)
+}
+
+// ProcessThreadCommand processes the specified command on the thread of the channel.
+func (s *terminalService) ProcessThreadCommand(channelID, threadID, command string) {
+	go s.processCommand(context.Background(), channelID, threadID, command)
+}
+
+func (s *terminalService) processCommand(ctx context.Context, channelID, threadID, command string) error {
+	return s.send(ctx, &service.Event{
+		Type: service.MessageEvent,
+		Data: local.NewCommandMessage(s, channelID, threadID, command),
+	})
+}
+
+// Channel returns a channel of specified channelID.
+func (s *terminalService) Channel(channelID string) plugin.Channel {
+	return local.NewChannel(channelID, channelID)
+}
+
+// User returns a user of specified userID.
+func (s *terminalService) User(userID string) plugin.User {
+	return local.NewUser(userID, userID == s.botUserID)
+}
+
+// SetEphemeralHelp implements the service.EphemeralHelper interface.
+func (s *terminalService) SetEphemeralHelp(ephemeral bool) {
+	s.ephemeralHelp = ephemeral
+}
+
+// EphemeralHelp implements the local.Service interface.
+// It returns whether help messages are posted ephemerally.
+func (s *terminalService) EphemeralHelp() bool {
+	return s.ephemeralHelp
+}
+
+// EscapeHelp implements the service.Service interface.
+func (s *terminalService) EscapeHelp(help string) string {
+	return help
+}
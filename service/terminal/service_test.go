@@ -0,0 +1,137 @@
+package terminal_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	bot "github.com/kechako/gopher-bot/v2"
+	"github.com/kechako/gopher-bot/v2/plugin"
+	"github.com/kechako/gopher-bot/v2/service/terminal"
+)
+
+// echoPlugin replies to "echo <text>" and "mention <text>" messages.
+type echoPlugin struct{}
+
+func (p *echoPlugin) Hello(ctx context.Context, hello plugin.Hello) {}
+
+func (p *echoPlugin) DoAction(ctx context.Context, msg plugin.Message) {
+	command, text, _ := strings.Cut(msg.Text(), " ")
+	switch command {
+	case "echo":
+		msg.Post(text)
+	case "mention":
+		msg.Mention(text)
+	}
+}
+
+func (p *echoPlugin) Help(ctx context.Context) *plugin.Help {
+	return &plugin.Help{
+		Name: "echo",
+		Commands: []*plugin.Command{
+			{Command: "echo <text>", Description: "Echo the text."},
+		},
+	}
+}
+
+// run runs a bot with the echo plugin on the terminal service that reads the script,
+// and returns the output. The bot must stop at the end of the script.
+func run(t *testing.T, script string) string {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var out bytes.Buffer
+	s, err := terminal.New(&terminal.Config{
+		Logger: logger,
+		In:     strings.NewReader(script),
+		Out:    &out,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := bot.New(s, bot.WithLogger(logger), bot.WithDatabaseDir(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	b.AddPlugin(&echoPlugin{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := b.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("the bot does not stop at the end of the input")
+	}
+
+	return out.String()
+}
+
+var scriptTests = map[string]struct {
+	script string
+	want   string
+}{
+	"post": {
+		script: "echo hello\n",
+		want:   "[#general] gopher-bot: hello\n",
+	},
+	"mention": {
+		script: "mention hello\n",
+		want:   "[#general] gopher-bot: @user hello\n",
+	},
+	"meta-commands": {
+		script: "/channel #random\n/user @alice\nmention hi\n/unknown\n",
+		want: "* channel is changed to #random\n" +
+			"* user is changed to @alice\n" +
+			"[#random] gopher-bot: @alice hi\n" +
+			"* usage: /channel <channel>, /user <user>, /react <id> <emoji>, /unreact <id> <emoji>, /click <id> <action> [value]\n",
+	},
+	"order": {
+		script: "echo 1\n\necho 2\necho 3",
+		want: "[#general] gopher-bot: 1\n" +
+			"[#general] gopher-bot: 2\n" +
+			"[#general] gopher-bot: 3\n",
+	},
+	"help": {
+		script: "@gopher-bot help\n",
+		want:   "[#general] gopher-bot: echo: \n    echo <text>: Echo the text.\n",
+	},
+	"empty": {
+		script: "",
+		want:   "",
+	},
+}
+
+func Test_Service(t *testing.T) {
+	for name, tt := range scriptTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			got := run(t, tt.script)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("output differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Service_Close_notStarted(t *testing.T) {
+	s, err := terminal.New(&terminal.Config{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("Close() before Start => error %v", err)
+	}
+}
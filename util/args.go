@@ -0,0 +1,277 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrUnterminatedQuote is the error used for the quote that is not closed.
+	ErrUnterminatedQuote = errors.New("unterminated quote")
+)
+
+// closing quotes of the opening quotes.
+// Typographic quotes are also supported because some clients convert quotes automatically.
+var quotes = map[rune]rune{
+	'"':  '"',
+	'\'': '\'',
+	'“':  '”',
+	'‘':  '’',
+}
+
+type arg struct {
+	value string
+	start int
+}
+
+// Args represents arguments parsed from a command line.
+type Args struct {
+	line string
+	args []arg
+	// err is the error of the rest of the line after args, that starts at errStart.
+	err      error
+	errStart int
+}
+
+// ParseArgs parses the line into arguments like a shell.
+//
+// Arguments are separated by white spaces including new lines.
+// Single quotes preserve the literal value of each character within the quotes.
+// Double quotes preserve the literal value of each character within the quotes,
+// with the exception of \" and \\.
+// Outside of quotes, a backslash preserves the literal value of the next character.
+//
+// If a quote is not closed, it returns ErrUnterminatedQuote together with the
+// arguments before the unterminated one, so that the rest of the line can still
+// be taken as it is by Rest.
+func ParseArgs(line string) (*Args, error) {
+	var (
+		args    []arg
+		value   strings.Builder
+		start   = -1
+		quote   rune
+		escaped bool
+	)
+
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+			if quote != 0 && r != '"' && r != '\\' {
+				value.WriteRune('\\')
+			}
+			value.WriteRune(r)
+		case quote != 0:
+			switch {
+			case r == quote:
+				quote = 0
+			case r == '\\' && quote == '"':
+				escaped = true
+			default:
+				value.WriteRune(r)
+			}
+		case unicode.IsSpace(r):
+			if start >= 0 {
+				args = append(args, arg{value: value.String(), start: start})
+				value.Reset()
+				start = -1
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+			if closing, ok := quotes[r]; ok {
+				quote = closing
+			} else if r == '\\' {
+				escaped = true
+			} else {
+				value.WriteRune(r)
+			}
+		}
+	}
+
+	if quote != 0 {
+		return &Args{
+			line:     line,
+			args:     args,
+			err:      ErrUnterminatedQuote,
+			errStart: start,
+		}, ErrUnterminatedQuote
+	}
+	if escaped {
+		value.WriteRune('\\')
+	}
+	if start >= 0 {
+		args = append(args, arg{value: value.String(), start: start})
+	}
+
+	return &Args{
+		line: line,
+		args: args,
+	}, nil
+}
+
+// SplitArgs splits the line into arguments like a shell.
+// See ParseArgs for details.
+func SplitArgs(line string) ([]string, error) {
+	args, err := ParseArgs(line)
+	if err != nil {
+		return nil, err
+	}
+
+	return args.Strings(), nil
+}
+
+// Quote quotes the value to be parsed as one argument by ParseArgs.
+// Returns the value as it is if it does not need to be quoted.
+func Quote(value string) string {
+	if value == "" {
+		return "''"
+	}
+	if !strings.ContainsFunc(value, needsQuote) {
+		return value
+	}
+
+	// a single quote is written as '\'' because it cannot be escaped within single quotes
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func needsQuote(r rune) bool {
+	if _, ok := quotes[r]; ok {
+		return true
+	}
+	return r == '\\' || r == '”' || r == '’' || unicode.IsSpace(r)
+}
+
+// Len returns the number of the arguments.
+// The unterminated argument is not counted.
+func (a *Args) Len() int {
+	return len(a.args)
+}
+
+// Arg returns the i-th argument.
+// Returns an empty string if the argument does not exist.
+func (a *Args) Arg(i int) string {
+	if i < 0 || i >= len(a.args) {
+		return ""
+	}
+
+	return a.args[i].value
+}
+
+// Strings returns all the arguments.
+func (a *Args) Strings() []string {
+	values := make([]string, len(a.args))
+	for i, arg := range a.args {
+		values[i] = arg.value
+	}
+
+	return values
+}
+
+// Rest returns the rest of the line from the i-th argument as it is,
+// including quotes and white spaces between the arguments.
+// If i is Len() and the line has an unterminated argument, it returns the rest from the argument.
+func (a *Args) Rest(i int) string {
+	var start int
+	switch {
+	case i >= 0 && i < len(a.args):
+		start = a.args[i].start
+	case i == len(a.args) && a.err != nil:
+		start = a.errStart
+	default:
+		return ""
+	}
+
+	return strings.TrimRightFunc(a.line[start:], unicode.IsSpace)
+}
+
+// Err returns the error of the rest of the line after the arguments, such as ErrUnterminatedQuote.
+func (a *Args) Err() error {
+	return a.err
+}
+
+// Slice returns the arguments from the i-th argument.
+func (a *Args) Slice(i int) *Args {
+	if i > len(a.args) {
+		i = len(a.args)
+	}
+
+	return &Args{
+		line:     a.line,
+		args:     a.args[i:],
+		err:      a.err,
+		errStart: a.errStart,
+	}
+}
+
+// Flags parses leading options of the arguments, and returns the options and the rest of the arguments.
+//
+// Options are specified in the form of --name=value, --name value, -name=value or -name value.
+// Options listed in valueNames take a value, and the other options are set to "true".
+// Options are parsed until the first non-option argument or "--".
+// Arguments that look like a negative number are not options.
+func (a *Args) Flags(valueNames ...string) (map[string]string, *Args, error) {
+	flags := make(map[string]string)
+
+	i := 0
+	for ; i < len(a.args); i++ {
+		value := a.args[i].value
+		if value == "--" {
+			i++
+			break
+		}
+		if !isFlag(value) {
+			break
+		}
+
+		name := strings.TrimLeft(value, "-")
+		if n, v, ok := strings.Cut(name, "="); ok {
+			flags[n] = v
+			continue
+		}
+
+		if !contains(valueNames, name) {
+			flags[name] = "true"
+			continue
+		}
+
+		if i+1 >= len(a.args) {
+			return nil, nil, fmt.Errorf("flag needs an argument: %s", value)
+		}
+		i++
+		flags[name] = a.args[i].value
+	}
+
+	return flags, a.Slice(i), nil
+}
+
+func isFlag(s string) bool {
+	if len(s) < 2 || s[0] != '-' {
+		return false
+	}
+
+	name := strings.TrimPrefix(s[1:], "-")
+	if name == "" || name[0] == '=' {
+		return false
+	}
+
+	// negative number
+	if s[1] == '.' || (s[1] >= '0' && s[1] <= '9') {
+		return false
+	}
+
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
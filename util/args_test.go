@@ -0,0 +1,161 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var parseArgsTests = map[string]struct {
+	line string
+	args []string
+	err  error
+}{
+	"empty":           {line: "", args: []string{}},
+	"spaces":          {line: "  a  b\tc\nd  ", args: []string{"a", "b", "c", "d"}},
+	"double quotes":   {line: `loc add "New York" 40.7 -74.0`, args: []string{"loc", "add", "New York", "40.7", "-74.0"}},
+	"single quotes":   {line: `echo 'a "b" \c'`, args: []string{"echo", `a "b" \c`}},
+	"escape in quote": {line: `echo "a \"b\" \\ \c"`, args: []string{"echo", `a "b" \ \c`}},
+	"escape":          {line: `echo a\ b \"c\"`, args: []string{"echo", "a b", `"c"`}},
+	"concatenated":    {line: `echo a"b c"'d'`, args: []string{"echo", "ab cd"}},
+	"empty quotes":    {line: `echo "" ''`, args: []string{"echo", "", ""}},
+	"new line":        {line: "echo \"a\nb\"", args: []string{"echo", "a\nb"}},
+	"typographic":     {line: "loc add “New York” ‘a b’", args: []string{"loc", "add", "New York", "a b"}},
+	"unterminated":    {line: `echo "a b`, err: ErrUnterminatedQuote},
+}
+
+func Test_ParseArgs(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range parseArgsTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			args, err := SplitArgs(tt.line)
+			if err != tt.err {
+				t.Fatalf("SplitArgs(%q) => error %v, want %v", tt.line, err, tt.err)
+			}
+			if diff := cmp.Diff(args, tt.args); diff != "" {
+				t.Errorf("SplitArgs(%q) differs: (-got +want)\n%s", tt.line, diff)
+			}
+		})
+	}
+}
+
+var quoteTests = map[string]struct {
+	value  string
+	quoted string
+}{
+	"plain":        {value: "weather", quoted: "weather"},
+	"empty":        {value: "", quoted: "''"},
+	"space":        {value: "New York", quoted: "'New York'"},
+	"single quote": {value: "it's", quoted: `'it'\''s'`},
+	"double quote": {value: `a "b"`, quoted: `'a "b"'`},
+	"backslash":    {value: `a\b`, quoted: `'a\b'`},
+	"typographic":  {value: "“a”", quoted: "'“a”'"},
+}
+
+func Test_Quote(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range quoteTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			quoted := Quote(tt.value)
+			if quoted != tt.quoted {
+				t.Errorf("Quote(%q) => %q, want %q", tt.value, quoted, tt.quoted)
+			}
+
+			args, err := SplitArgs(quoted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(args, []string{tt.value}); diff != "" {
+				t.Errorf("SplitArgs(Quote(%q)) differs: (-got +want)\n%s", tt.value, diff)
+			}
+		})
+	}
+}
+
+func Test_Args_Rest(t *testing.T) {
+	t.Parallel()
+
+	args, err := ParseArgs(`cron add name 0 9 * * 1-5 weather   "New York"  `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rest, want := args.Rest(8), `weather   "New York"`; rest != want {
+		t.Errorf("Args.Rest(8) => %q, want %q", rest, want)
+	}
+	if rest, want := args.Slice(9).Rest(0), `"New York"`; rest != want {
+		t.Errorf("Args.Slice(9).Rest(0) => %q, want %q", rest, want)
+	}
+	if rest := args.Rest(10); rest != "" {
+		t.Errorf("Args.Rest(10) => %q, want empty", rest)
+	}
+}
+
+func Test_Args_Rest_unterminated(t *testing.T) {
+	t.Parallel()
+
+	args, err := ParseArgs(`cron add name echo don't  `)
+	if err != ErrUnterminatedQuote {
+		t.Fatalf("ParseArgs() => error %v, want %v", err, ErrUnterminatedQuote)
+	}
+
+	if diff := cmp.Diff(args.Strings(), []string{"cron", "add", "name", "echo"}); diff != "" {
+		t.Errorf("Args.Strings() differs: (-got +want)\n%s", diff)
+	}
+	if rest, want := args.Rest(3), "echo don't"; rest != want {
+		t.Errorf("Args.Rest(3) => %q, want %q", rest, want)
+	}
+	if rest, want := args.Slice(4).Rest(0), "don't"; rest != want {
+		t.Errorf("Args.Slice(4).Rest(0) => %q, want %q", rest, want)
+	}
+	if err := args.Slice(4).Err(); err != ErrUnterminatedQuote {
+		t.Errorf("Args.Slice(4).Err() => %v, want %v", err, ErrUnterminatedQuote)
+	}
+}
+
+var flagsTests = map[string]struct {
+	line  string
+	flags map[string]string
+	args  []string
+}{
+	"long":            {line: "--name=a --bool x y", flags: map[string]string{"name": "a", "bool": "true"}, args: []string{"x", "y"}},
+	"value":           {line: "--value a x", flags: map[string]string{"value": "a"}, args: []string{"x"}},
+	"short":           {line: "-v a -b x", flags: map[string]string{"v": "a", "b": "true"}, args: []string{"x"}},
+	"terminator":      {line: "-b -- --value x", flags: map[string]string{"b": "true"}, args: []string{"--value", "x"}},
+	"stop":            {line: "x --value a", flags: map[string]string{}, args: []string{"x", "--value", "a"}},
+	"negative number": {line: "-1 -0.5", flags: map[string]string{}, args: []string{"-1", "-0.5"}},
+}
+
+func Test_Args_Flags(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range flagsTests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			args, err := ParseArgs(tt.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			flags, rest, err := args.Flags("value", "v")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(flags, tt.flags); diff != "" {
+				t.Errorf("Args.Flags() flags differs: (-got +want)\n%s", diff)
+			}
+			if diff := cmp.Diff(rest.Strings(), tt.args); diff != "" {
+				t.Errorf("Args.Flags() args differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+
+	args, _ := ParseArgs("--value")
+	if _, _, err := args.Flags("value"); err == nil {
+		t.Error("Args.Flags() must return an error if the value is missing")
+	}
+}